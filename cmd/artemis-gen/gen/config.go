@@ -0,0 +1,94 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gen
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// FieldBinding maps a single InputObject field to the Go field that holds its coerced value.
+type FieldBinding struct {
+	// Name is the field's name as declared in the schema.
+	Name string `yaml:"name"`
+
+	// GoField is the destination struct field name.
+	GoField string `yaml:"goField"`
+
+	// GoType is the Go type the field coerces to, used to type-assert the value that
+	// value.CoerceValue returns for this field, e.g. "string" or "*model.Address".
+	GoType string `yaml:"goType"`
+}
+
+// TypeBinding maps a single InputObject to the Go struct it should coerce into.
+type TypeBinding struct {
+	// GraphQLType is the InputObject's name as declared in the schema; used only in generated
+	// error messages.
+	GraphQLType string `yaml:"graphqlType"`
+
+	// GoType is the qualified Go struct type to construct, e.g. "model.CreateUserInput".
+	GoType string `yaml:"goType"`
+
+	// TypeVar is the qualified Go identifier of the *graphql.InputObject variable that defines
+	// GraphQLType in the schema, e.g. "schema.CreateUserInputType". artemis-gen registers the
+	// generated coercer against this type with value.RegisterInputCoercer.
+	TypeVar string `yaml:"typeVar"`
+
+	// Fields lists every InputObject field and where its coerced value should be written.
+	Fields []FieldBinding `yaml:"fields"`
+}
+
+// Config describes a single artemis-gen invocation.
+type Config struct {
+	// Package is the Go package name the generated file belongs to.
+	Package string `yaml:"package"`
+
+	// Imports maps an import alias to its import path; GoType/TypeVar/FieldBinding.GoType
+	// reference types via "alias.Identifier".
+	Imports map[string]string `yaml:"imports"`
+
+	// OutputPath is where the generated file is written. Defaults to "input_gen.go" when empty.
+	OutputPath string `yaml:"-"`
+
+	// Bindings lists every InputObject that should get a generated coercer. Types not listed here
+	// continue to go through graphql/value's reflective CoerceValue.
+	Bindings []TypeBinding `yaml:"bindings"`
+}
+
+// LoadConfig reads and parses the generator config at path.
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config %q: %w", path, err)
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parsing config %q: %w", path, err)
+	}
+
+	if config.Package == "" {
+		return nil, fmt.Errorf("config %q: \"package\" is required", path)
+	}
+	if config.OutputPath == "" {
+		config.OutputPath = "input_gen.go"
+	}
+
+	return &config, nil
+}
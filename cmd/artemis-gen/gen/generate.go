@@ -0,0 +1,144 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+// Package gen implements the code generation performed by cmd/artemis-gen.
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+var coercerTemplate = template.Must(template.New("coercer").Parse(`
+func init() {
+	value.RegisterInputCoercer({{.TypeVar}}, {{.FuncName}})
+}
+
+// {{.FuncName}} is a generated, reflection-free replacement for the dynamic coercion that
+// value.CoerceValue would otherwise perform for {{.GraphQLType}}. See cmd/artemis-gen.
+func {{.FuncName}}(v interface{}) (interface{}, graphql.Errors) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, graphql.Errors{
+			value.NewCoercionError(
+				"Expected type {{.GraphQLType}} to be an object", nil, "", "", nil),
+		}
+	}
+
+	fieldNames := []string{ {{range .Fields}}"{{.Name}}", {{end}} }
+	var errs graphql.Errors
+	for name := range obj {
+		found := false
+		for _, candidate := range fieldNames {
+			if candidate == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, value.NewCoercionError(
+				fmt.Sprintf("Field %q is not defined by type {{.GraphQLType}}", name),
+				nil, "", value.DidYouMean(name, fieldNames), nil))
+		}
+	}
+
+	var out {{.GoType}}
+	fields := {{.TypeVar}}.Fields()
+{{range .Fields}}
+	if fieldValue, ok := obj["{{.Name}}"]; ok {
+		coerced, fieldErrs := value.CoerceValue(fieldValue, fields["{{.Name}}"].Type(), nil)
+		if len(fieldErrs) > 0 {
+			errs = append(errs, fieldErrs...)
+		} else if len(errs) == 0 {
+			out.{{.GoField}}, _ = coerced.({{.GoType}})
+		}
+	} else if field := fields["{{.Name}}"]; field.HasDefaultValue() {
+		out.{{.GoField}}, _ = field.DefaultValue().({{.GoType}})
+	} else if graphql.IsNonNullType(field.Type()) {
+		errs = append(errs, value.NewCoercionError(
+			fmt.Sprintf("Field {{.Name}} of required type %v was not provided", field.Type()),
+			nil, "", "", nil))
+	}
+{{end}}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return out, nil
+}
+`))
+
+// coercerContext is the per-binding template context.
+type coercerContext struct {
+	TypeBinding
+	FuncName string
+}
+
+// Generate runs the generator described by config, writing the resulting Go source to
+// config.OutputPath.
+func Generate(config *Config) error {
+	if len(config.Bindings) == 0 {
+		return fmt.Errorf("no bindings configured; nothing to generate")
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by artemis-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", config.Package)
+
+	fmt.Fprintf(&buf, "import (\n\t\"fmt\"\n\n\t\"github.com/botobag/artemis/graphql\"\n\t\"github.com/botobag/artemis/graphql/value\"\n")
+	for _, alias := range sortedAliases(config.Imports) {
+		fmt.Fprintf(&buf, "\t%s %q\n", alias, config.Imports[alias])
+	}
+	fmt.Fprintf(&buf, ")\n")
+
+	for _, binding := range config.Bindings {
+		ctx := coercerContext{
+			TypeBinding: binding,
+			FuncName:    "coerce" + exportedName(binding.GraphQLType),
+		}
+		if err := coercerTemplate.Execute(&buf, ctx); err != nil {
+			return fmt.Errorf("generating coercer for %q: %w", binding.GraphQLType, err)
+		}
+	}
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		// Emit the unformatted source anyway so the caller can inspect what went wrong.
+		return fmt.Errorf("formatting generated source: %w\n%s", err, buf.String())
+	}
+
+	return ioutil.WriteFile(config.OutputPath, formatted, 0644)
+}
+
+func sortedAliases(imports map[string]string) []string {
+	aliases := make([]string, 0, len(imports))
+	for alias := range imports {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
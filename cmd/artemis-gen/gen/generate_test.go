@@ -0,0 +1,96 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package gen
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportedNameUppercasesFirstRune(t *testing.T) {
+	cases := map[string]string{
+		"createUserInput": "CreateUserInput",
+		"CreateUserInput": "CreateUserInput",
+		"":                "",
+	}
+	for in, want := range cases {
+		if got := exportedName(in); got != want {
+			t.Errorf("exportedName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSortedAliasesIsDeterministic(t *testing.T) {
+	imports := map[string]string{
+		"zebra": "example.com/zebra",
+		"apple": "example.com/apple",
+		"mango": "example.com/mango",
+	}
+	got := sortedAliases(imports)
+	want := []string{"apple", "mango", "zebra"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("sortedAliases(...) = %v, want %v", got, want)
+	}
+}
+
+func TestGenerateWritesFormattedGoSourceWithCoercerAndRegistration(t *testing.T) {
+	dir := t.TempDir()
+	outputPath := filepath.Join(dir, "input_gen.go")
+
+	config := &Config{
+		Package:    "model",
+		OutputPath: outputPath,
+		Bindings: []TypeBinding{
+			{
+				GraphQLType: "CreateUserInput",
+				GoType:      "model.CreateUserInput",
+				TypeVar:     "schema.CreateUserInputType",
+				Fields: []FieldBinding{
+					{Name: "name", GoField: "Name", GoType: "string"},
+				},
+			},
+		},
+	}
+
+	if err := Generate(config); err != nil {
+		t.Fatalf("Generate(...) returned error: %v", err)
+	}
+
+	source, err := ioutil.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("reading generated file: %v", err)
+	}
+
+	got := string(source)
+	if !strings.Contains(got, "package model") {
+		t.Error("generated source missing \"package model\"")
+	}
+	if !strings.Contains(got, "func coerceCreateUserInput(v interface{}) (interface{}, graphql.Errors)") {
+		t.Error("generated source missing the exported coercer function for CreateUserInput")
+	}
+	if !strings.Contains(got, "value.RegisterInputCoercer(schema.CreateUserInputType, coerceCreateUserInput)") {
+		t.Error("generated source missing the init() registration call")
+	}
+}
+
+func TestGenerateRejectsEmptyBindings(t *testing.T) {
+	if err := Generate(&Config{Package: "model", OutputPath: filepath.Join(t.TempDir(), "out.go")}); err == nil {
+		t.Error("Generate(config with no bindings) returned no error")
+	}
+}
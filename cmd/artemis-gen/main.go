@@ -0,0 +1,54 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+// Command artemis-gen emits a reflection-free fast path for input coercion. Given a GraphQL
+// schema and a config describing which Go type backs each InputObject, Enum and Scalar, it writes
+// a "*_gen.go" file that registers a hand-rolled value.InputCoercerFunc for every mapped type via
+// value.RegisterInputCoercer (see graphql/value). CoerceValue prefers a registered coercer over
+// the reflective implementation, so generated code only pays for what the schema actually
+// declares.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/botobag/artemis/cmd/artemis-gen/gen"
+)
+
+func main() {
+	var (
+		configPath = flag.String("config", "artemis-gen.yml", "path to the generator config file")
+		outPath    = flag.String("out", "", "path of the generated *_gen.go file (defaults to <package>/input_gen.go)")
+	)
+	flag.Parse()
+
+	config, err := gen.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("artemis-gen: %v", err)
+	}
+
+	if *outPath != "" {
+		config.OutputPath = *outPath
+	}
+
+	if err := gen.Generate(config); err != nil {
+		fmt.Fprintf(os.Stderr, "artemis-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,54 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+// Package errctx seeds graphql.Error's diagnostic Context with request-scoped tags carried on a
+// context.Context, so middleware can attach them once (with WithValue) and have them appear on
+// every Error built while handling the request, via errctx.From(ctx) passed straight to
+// graphql.NewError, without every call site repeating them.
+package errctx
+
+import (
+	"context"
+
+	"github.com/botobag/artemis/graphql"
+)
+
+// key is the unexported type WithValue stores its graphql.ErrorContext under, so it can't collide
+// with a key any other package stores on the same context.Context.
+type key struct{}
+
+// WithValue returns a copy of ctx with (tagKey, value) added to the tags errctx.From(ctx) returns,
+// alongside whatever WithValue calls higher up the context chain already added.
+func WithValue(ctx context.Context, tagKey string, value interface{}) context.Context {
+	tags := From(ctx)
+
+	merged := make(graphql.ErrorContext, len(tags)+1)
+	for k, v := range tags {
+		merged[k] = v
+	}
+	merged[tagKey] = value
+
+	return context.WithValue(ctx, key{}, merged)
+}
+
+// From returns the graphql.ErrorContext accumulated on ctx via WithValue, or nil if none was ever
+// attached. Pass it straight to graphql.NewError (it implements the ErrorContext NewError arg) so
+// every error built while handling ctx's request picks up whatever tags middleware attached —
+// request ID, tenant, etc. — automatically.
+func From(ctx context.Context) graphql.ErrorContext {
+	tags, _ := ctx.Value(key{}).(graphql.ErrorContext)
+	return tags
+}
@@ -0,0 +1,60 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package errctx
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFromReturnsNilWithoutWithValue(t *testing.T) {
+	if tags := From(context.Background()); tags != nil {
+		t.Errorf("From(context.Background()) = %v, want nil", tags)
+	}
+}
+
+func TestWithValueAccumulatesAcrossCalls(t *testing.T) {
+	ctx := context.Background()
+	ctx = WithValue(ctx, "requestID", "abc123")
+	ctx = WithValue(ctx, "tenant", "acme")
+
+	tags := From(ctx)
+	if tags["requestID"] != "abc123" || tags["tenant"] != "acme" {
+		t.Errorf("From(ctx) = %v, want both requestID and tenant tags", tags)
+	}
+}
+
+func TestWithValueDoesNotMutateParentContextsTags(t *testing.T) {
+	parent := WithValue(context.Background(), "requestID", "abc123")
+	child := WithValue(parent, "tenant", "acme")
+
+	if _, ok := From(parent)["tenant"]; ok {
+		t.Error("From(parent) unexpectedly picked up a tag added via a child context")
+	}
+	if From(child)["requestID"] != "abc123" {
+		t.Error("From(child) lost a tag set on its parent")
+	}
+}
+
+func TestWithValueLaterCallOverridesSameKey(t *testing.T) {
+	ctx := WithValue(context.Background(), "tenant", "acme")
+	ctx = WithValue(ctx, "tenant", "globex")
+
+	if got := From(ctx)["tenant"]; got != "globex" {
+		t.Errorf(`From(ctx)["tenant"] = %v, want "globex"`, got)
+	}
+}
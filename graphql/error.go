@@ -18,11 +18,15 @@ package graphql
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"reflect"
 	"runtime"
 	"strconv"
+	"strings"
+	"sync/atomic"
 
 	"github.com/botobag/artemis/internal/util"
 )
@@ -61,6 +65,26 @@ func (k ErrKind) String() string {
 	return "unknown error kind"
 }
 
+// errKind is a sentinel error wrapping an ErrKind, for use as the target of errors.Is. It matches
+// any *Error whose Kind is the same, non-zero value; see (*Error).Is.
+type errKind ErrKind
+
+// Error implements Go's error interface.
+func (k errKind) Error() string {
+	return ErrKind(k).String()
+}
+
+// Sentinel errors for each ErrKind but ErrKindOther (which means "unclassified", so has nothing in
+// particular to match). They let callers write, e.g., errors.Is(err, graphql.ErrSyntax) instead of
+// type-asserting err to *Error and comparing Kind by hand.
+var (
+	ErrCoercion   error = errKind(ErrKindCoercion)
+	ErrSyntax     error = errKind(ErrKindSyntax)
+	ErrValidation error = errKind(ErrKindValidation)
+	ErrExecution  error = errKind(ErrKindExecution)
+	ErrInternal   error = errKind(ErrKindInternal)
+)
+
 // ErrorExtensions provides an additional entry to a GraphQL error with key "extensions". It is
 // useful for attaching vendor-specific error data (such as error code).
 //
@@ -99,6 +123,30 @@ func (path *ResponsePath) AppendIndex(index int) {
 	path.keys = append(path.keys, index)
 }
 
+// AppendEdgeIndex adds a Relay Connection edge at index i to the end of current path: the field
+// name "edges" followed by i, the pair a connection field puts between itself and one of its
+// edges.
+func (path *ResponsePath) AppendEdgeIndex(i int) {
+	path.keys = append(path.keys, "edges", i)
+}
+
+// AppendNode adds a Relay Connection edge's "node" field to the end of current path, the field an
+// edge puts between itself and the node it wraps.
+func (path *ResponsePath) AppendNode() {
+	path.keys = append(path.keys, "node")
+}
+
+// Len reports the number of keys in path.
+func (path *ResponsePath) Len() int {
+	return len(path.keys)
+}
+
+// Key returns the i'th key in path: a string field name or an int list index. It panics if i is
+// out of range, the same contract slice indexing has.
+func (path *ResponsePath) Key(i int) interface{} {
+	return path.keys[i]
+}
+
 // Clone makes a deep copy of the path.
 func (path *ResponsePath) Clone() *ResponsePath {
 	if len(path.keys) == 0 {
@@ -110,6 +158,42 @@ func (path *ResponsePath) Clone() *ResponsePath {
 	return &ResponsePath{keys}
 }
 
+// Parent returns path with its last key dropped, as a new ResponsePath, or an empty path if path
+// is already empty.
+func (path *ResponsePath) Parent() *ResponsePath {
+	if len(path.keys) == 0 {
+		return &ResponsePath{}
+	}
+	return path.Head(len(path.keys) - 1)
+}
+
+// Head returns the first n keys of path as a new ResponsePath. n is clamped to path.Len().
+func (path *ResponsePath) Head(n int) *ResponsePath {
+	if n > len(path.keys) {
+		n = len(path.keys)
+	}
+	if n <= 0 {
+		return &ResponsePath{}
+	}
+	keys := make([]interface{}, n)
+	copy(keys, path.keys[:n])
+	return &ResponsePath{keys}
+}
+
+// Tail returns path with its first n keys dropped, as a new ResponsePath. n is clamped to
+// path.Len().
+func (path *ResponsePath) Tail(n int) *ResponsePath {
+	if n > len(path.keys) {
+		n = len(path.keys)
+	}
+	if n <= 0 {
+		return path.Clone()
+	}
+	keys := make([]interface{}, len(path.keys)-n)
+	copy(keys, path.keys[n:])
+	return &ResponsePath{keys}
+}
+
 // MarshalJSON serializes path keys to JSON.
 func (path *ResponsePath) MarshalJSON() ([]byte, error) {
 	return json.Marshal(path.keys)
@@ -139,6 +223,98 @@ func (path *ResponsePath) String() string {
 	return b.String()
 }
 
+// pathSegmentKind distinguishes the two kinds of key a ResponsePath can hold, for pathSegmentMatcher.
+type pathSegmentKind uint8
+
+const (
+	fieldSegment pathSegmentKind = iota
+	indexSegment
+)
+
+// pathSegmentMatcher matches one key of a ResponsePath. A wildcard segment (from a "*" in the
+// pattern) matches any key of its kind; otherwise the key must equal literal.
+type pathSegmentMatcher struct {
+	kind     pathSegmentKind
+	literal  interface{} // string for fieldSegment, int for indexSegment; unused if wildcard
+	wildcard bool
+}
+
+// PathMatcher matches a ResponsePath against a glob-like pattern, such as
+// "users.*.posts.edges[*].node.title": "." separates field names, "[n]" an exact list index and
+// "[*]" any list index, and a bare "*" any single field name. It's built with ParsePathMatcher.
+type PathMatcher struct {
+	segments []pathSegmentMatcher
+}
+
+// ParsePathMatcher compiles pattern into a PathMatcher, or returns an error if pattern is
+// malformed (an unterminated "[" or a bracket that's neither "*" nor a base-10 integer).
+func ParsePathMatcher(pattern string) (*PathMatcher, error) {
+	var segments []pathSegmentMatcher
+
+	for _, piece := range strings.Split(pattern, ".") {
+		field := piece
+		var brackets []string
+
+		if i := strings.IndexByte(piece, '['); i >= 0 {
+			field = piece[:i]
+			rest := piece[i:]
+			for len(rest) > 0 {
+				end := strings.IndexByte(rest, ']')
+				if rest[0] != '[' || end < 0 {
+					return nil, fmt.Errorf("graphql: malformed path pattern %q", pattern)
+				}
+				brackets = append(brackets, rest[1:end])
+				rest = rest[end+1:]
+			}
+		}
+
+		if field == "*" {
+			segments = append(segments, pathSegmentMatcher{kind: fieldSegment, wildcard: true})
+		} else if field != "" {
+			segments = append(segments, pathSegmentMatcher{kind: fieldSegment, literal: field})
+		}
+
+		for _, bracket := range brackets {
+			if bracket == "*" {
+				segments = append(segments, pathSegmentMatcher{kind: indexSegment, wildcard: true})
+				continue
+			}
+			index, err := strconv.Atoi(bracket)
+			if err != nil {
+				return nil, fmt.Errorf("graphql: malformed path pattern %q: bad index %q", pattern, bracket)
+			}
+			segments = append(segments, pathSegmentMatcher{kind: indexSegment, literal: index})
+		}
+	}
+
+	return &PathMatcher{segments: segments}, nil
+}
+
+// Match reports whether path has exactly the keys m's pattern describes.
+func (m *PathMatcher) Match(path *ResponsePath) bool {
+	if path == nil || path.Len() != len(m.segments) {
+		return false
+	}
+
+	for i, seg := range m.segments {
+		key := path.Key(i)
+		switch seg.kind {
+		case fieldSegment:
+			name, ok := key.(string)
+			if !ok || (!seg.wildcard && name != seg.literal.(string)) {
+				return false
+			}
+
+		case indexSegment:
+			index, ok := key.(int)
+			if !ok || (!seg.wildcard && index != seg.literal.(int)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // ErrorWithPath indicates an error that contains a path for reporting. If "path" is not given in
 // the arguments to NewError, NewError will retrieve the one from the underlying error (if provided)
 // that implements this interface.
@@ -153,6 +329,57 @@ type ErrorWithExtensions interface {
 	Extensions() ErrorExtensions
 }
 
+// ErrorContext holds internal diagnostic tags for an Error — request ID, resolver name, a
+// variable snapshot, tenant, etc. Unlike Extensions, which is part of the GraphQL response and so
+// is user-facing and spec'd, Context is never serialized; it exists purely to help whoever ends up
+// debugging the error. WithContext builds a single-entry ErrorContext for passing to NewError;
+// passing more than one (or one with several entries) accumulates every key onto the new Error.
+type ErrorContext map[string]interface{}
+
+// WithContext builds the single-entry ErrorContext {key: value}, for passing to NewError.
+func WithContext(key string, value interface{}) ErrorContext {
+	return ErrorContext{key: value}
+}
+
+// ErrorWithStackTrace indicates an error that carries a captured call stack, analogous to
+// ErrorWithLocations and ErrorWithPath above. *Error implements it whenever stack trace capture
+// was enabled (see SetStackTraceCapture) when it, or the deepest *Error it wraps, was built by
+// NewError.
+type ErrorWithStackTrace interface {
+	StackTrace() []runtime.Frame
+}
+
+// stackTraceCaptureEnabled controls whether NewError captures a stack trace for the errors it
+// builds. It defaults to on; a production server that doesn't want to pay runtime.Callers' cost on
+// every error can turn it off with SetStackTraceCapture(false).
+var stackTraceCaptureEnabled int32 = 1
+
+// SetStackTraceCapture turns stack trace capture in NewError on or off, process-wide. It's enabled
+// by default.
+func SetStackTraceCapture(enabled bool) {
+	v := int32(0)
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&stackTraceCaptureEnabled, v)
+}
+
+// maxStackDepth bounds how many frames captureStack asks runtime.Callers for.
+const maxStackDepth = 32
+
+// captureStack returns the call stack for NewError's caller, in the form runtime.CallersFrames
+// expects, or nil if capture is currently disabled via SetStackTraceCapture.
+func captureStack() []uintptr {
+	if atomic.LoadInt32(&stackTraceCaptureEnabled) == 0 {
+		return nil
+	}
+	var pcs [maxStackDepth]uintptr
+	// Skip runtime.Callers, captureStack and NewError itself so the trace starts at NewError's
+	// caller, the same convention pkg/errors uses.
+	n := runtime.Callers(3, pcs[:])
+	return pcs[:n]
+}
+
 // An Error describes an error found during parse, validate or execute phases of performing a
 // GraphQL operation. It can be serialized to JSON for including in the response.
 //
@@ -199,6 +426,16 @@ type Error struct {
 
 	// Kind is the class of error
 	Kind ErrKind `json:"-"`
+
+	// Context holds internal diagnostic tags — request ID, resolver name, tenant, etc. — that
+	// accumulate as the error is wrapped up the resolver stack. Unlike Extensions, it's never
+	// serialized into the GraphQL response; see ErrorContext.
+	Context ErrorContext `json:"-"`
+
+	// stack holds the call stack captured by NewError, in the form runtime.CallersFrames expects.
+	// It's nil if stack trace capture was disabled (see SetStackTraceCapture) when this Error, or
+	// the deepest *Error it wraps, was built.
+	stack []uintptr
 }
 
 // Error implements Go error interface.
@@ -210,6 +447,7 @@ var _ error = (*Error)(nil)
 func NewError(message string, args ...interface{}) error {
 	e := &Error{
 		Message: message,
+		stack:   captureStack(),
 	}
 
 	for _, arg := range args {
@@ -225,6 +463,14 @@ func NewError(message string, args ...interface{}) error {
 		case ErrorExtensions:
 			e.Extensions = arg
 
+		case ErrorContext:
+			if e.Context == nil {
+				e.Context = make(ErrorContext, len(arg))
+			}
+			for k, v := range arg {
+				e.Context[k] = v
+			}
+
 		case error:
 			e.Err = arg
 
@@ -241,48 +487,64 @@ func NewError(message string, args ...interface{}) error {
 		}
 	}
 
-	// Propagate locations, path or extensions from underlying error when one is not provided in
-	// argument.
+	// Propagate locations, path, extensions or kind from underlying error when one is not provided
+	// in argument. prevErr, if found, is the closest *Error anywhere in prev's chain: prev itself
+	// when it's already an *Error, or one found by unwrapping through an intermediate
+	// fmt.Errorf("%w", ...) or pkg/errors wrapper.
 	prev := e.Err
 	if prev != nil {
+		var prevErr *Error
+		hasPrevErr := errors.As(prev, &prevErr)
+
 		if len(e.Locations) == 0 {
-			switch errWithLocations := prev.(type) {
-			case ErrorWithLocations:
+			if errWithLocations, ok := prev.(ErrorWithLocations); ok {
 				e.Locations = errWithLocations.Locations()
-			case *Error:
-				if len(errWithLocations.Locations) > 0 {
-					e.Locations = make([]ErrorLocation, len(errWithLocations.Locations))
-					copy(e.Locations, errWithLocations.Locations)
-				}
+			} else if hasPrevErr && len(prevErr.Locations) > 0 {
+				e.Locations = make([]ErrorLocation, len(prevErr.Locations))
+				copy(e.Locations, prevErr.Locations)
 			}
 		}
 
 		if e.Path == nil {
-			switch errWithPath := prev.(type) {
-			case ErrorWithPath:
+			if errWithPath, ok := prev.(ErrorWithPath); ok {
 				e.Path = errWithPath.Path()
-			case *Error:
-				if errWithPath.Path != nil {
-					e.Path = errWithPath.Path.Clone()
-				}
+			} else if hasPrevErr && prevErr.Path != nil {
+				e.Path = prevErr.Path.Clone()
 			}
 		}
 
 		if e.Extensions == nil {
-			switch errWithExtensions := prev.(type) {
-			case ErrorWithExtensions:
+			if errWithExtensions, ok := prev.(ErrorWithExtensions); ok {
 				e.Extensions = errWithExtensions.Extensions()
-			case *Error:
-				e.Extensions = errWithExtensions.Extensions
+			} else if hasPrevErr {
+				e.Extensions = prevErr.Extensions
 			}
 		}
 
 		// Pull kind from underlying error.
-		if e.Kind == ErrKindOther {
-			if prev, ok := prev.(*Error); ok {
-				e.Kind = prev.Kind
+		if e.Kind == ErrKindOther && hasPrevErr {
+			e.Kind = prevErr.Kind
+		}
+
+		// Merge in the prior error's context so tags accumulate as the error travels up the resolver
+		// stack, without letting an inherited tag override one set explicitly in args above.
+		if hasPrevErr && len(prevErr.Context) > 0 {
+			if e.Context == nil {
+				e.Context = make(ErrorContext, len(prevErr.Context))
+			}
+			for k, v := range prevErr.Context {
+				if _, ok := e.Context[k]; !ok {
+					e.Context[k] = v
+				}
 			}
 		}
+
+		// Prefer the deepest already-captured stack over the one just captured for e itself, so
+		// repeatedly wrapping an error doesn't bury its original site under a chain of wrap-site
+		// traces.
+		if hasPrevErr && len(prevErr.stack) > 0 {
+			e.stack = prevErr.stack
+		}
 	}
 
 	return e
@@ -306,6 +568,89 @@ func (e *Error) Error() string {
 	return b.String()
 }
 
+// Unwrap returns the underlying error, if any, so that errors.Is, errors.As and errors.Unwrap all
+// see through an Error to whatever it wraps.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target describes the same failure as e, for errors.Is. target may be one of
+// the ErrKind sentinel errors above (e.g. ErrSyntax), which matches any *Error of that Kind, or
+// another *Error, in which case e matches when their Kind is the same non-zero value and, if
+// target.Op is set, their Op also matches.
+func (e *Error) Is(target error) bool {
+	if kind, ok := target.(errKind); ok {
+		return e.Kind != ErrKindOther && e.Kind == ErrKind(kind)
+	}
+
+	t, ok := target.(*Error)
+	if !ok || e.Kind == ErrKindOther || e.Kind != t.Kind {
+		return false
+	}
+	return t.Op == "" || t.Op == e.Op
+}
+
+// As walks e's chain looking for an error assignable to target, which must be a non-nil pointer,
+// the same contract errors.As requires of its own target argument. It's defined here (rather than
+// relying solely on Unwrap and the standard library's own traversal) so that e.As can be called
+// directly without going through the errors package.
+func (e *Error) As(target interface{}) bool {
+	t := reflect.ValueOf(target)
+	if t.Kind() != reflect.Ptr || t.IsNil() {
+		return false
+	}
+	targetType := t.Elem().Type()
+
+	for err := error(e); err != nil; err = errors.Unwrap(err) {
+		v := reflect.ValueOf(err)
+		if v.Type().AssignableTo(targetType) {
+			t.Elem().Set(v)
+			return true
+		}
+	}
+	return false
+}
+
+// StackTrace returns the call stack captured when e, or the deepest *Error it wraps, was built by
+// NewError, or nil if stack trace capture was disabled (see SetStackTraceCapture) at the time.
+func (e *Error) StackTrace() []runtime.Frame {
+	if len(e.stack) == 0 {
+		return nil
+	}
+
+	frames := runtime.CallersFrames(e.stack)
+	trace := make([]runtime.Frame, 0, len(e.stack))
+	for {
+		frame, more := frames.Next()
+		trace = append(trace, frame)
+		if !more {
+			break
+		}
+	}
+	return trace
+}
+
+// Format implements fmt.Formatter so that, per the convention pkg/errors established, "%+v"
+// additionally appends e's captured stack trace (if any) after the ordinary error message; "%v"
+// and "%s" behave exactly like Error().
+func (e *Error) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if f.Flag('+') {
+			io.WriteString(f, e.Error())
+			for _, frame := range e.StackTrace() {
+				fmt.Fprintf(f, "\n\t%s\n\t\t%s:%d", frame.Function, frame.File, frame.Line)
+			}
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(f, e.Error())
+	case 'q':
+		fmt.Fprintf(f, "%q", e.Error())
+	}
+}
+
 func (e *Error) printError(b *util.StringBuilder, nextErr *Error) {
 	// If the previous error was also one of ours. Suppress duplications so the message won't contain
 	// the same kind, file name or user name twice.
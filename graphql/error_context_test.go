@@ -0,0 +1,47 @@
+/**
+ * Copyright (c) 2018, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package graphql
+
+import "testing"
+
+func TestErrorContextAccumulatesUpTheWrapChain(t *testing.T) {
+	inner := NewError("db timeout", WithContext("requestID", "abc123"))
+	outer := NewError("resolver failed", inner, WithContext("resolver", "Query.user")).(*Error)
+
+	if outer.Context["requestID"] != "abc123" {
+		t.Errorf(`outer.Context["requestID"] = %v, want "abc123"`, outer.Context["requestID"])
+	}
+	if outer.Context["resolver"] != "Query.user" {
+		t.Errorf(`outer.Context["resolver"] = %v, want "Query.user"`, outer.Context["resolver"])
+	}
+}
+
+func TestErrorContextExplicitTagWinsOverInherited(t *testing.T) {
+	inner := NewError("db timeout", WithContext("resolver", "Query.inner"))
+	outer := NewError("resolver failed", inner, WithContext("resolver", "Query.outer")).(*Error)
+
+	if outer.Context["resolver"] != "Query.outer" {
+		t.Errorf(`outer.Context["resolver"] = %v, want "Query.outer" (explicit tag should win)`, outer.Context["resolver"])
+	}
+}
+
+func TestWithContextBuildsSingleEntry(t *testing.T) {
+	tags := WithContext("tenant", "acme")
+	if len(tags) != 1 || tags["tenant"] != "acme" {
+		t.Errorf(`WithContext("tenant", "acme") = %v, want {"tenant": "acme"}`, tags)
+	}
+}
@@ -0,0 +1,202 @@
+/**
+ * Copyright (c) 2018, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package graphql
+
+import "testing"
+
+func buildTestPath() *ResponsePath {
+	path := &ResponsePath{}
+	path.AppendFieldName("user")
+	path.AppendFieldName("friends")
+	path.AppendEdgeIndex(0)
+	path.AppendNode()
+	path.AppendFieldName("name")
+	return path
+}
+
+func TestResponsePathAppendAndKey(t *testing.T) {
+	path := buildTestPath()
+
+	want := []interface{}{"user", "friends", "edges", 0, "node", "name"}
+	if path.Len() != len(want) {
+		t.Fatalf("path.Len() = %d, want %d", path.Len(), len(want))
+	}
+	for i, k := range want {
+		if path.Key(i) != k {
+			t.Errorf("path.Key(%d) = %v, want %v", i, path.Key(i), k)
+		}
+	}
+}
+
+func TestResponsePathAppendIndex(t *testing.T) {
+	path := &ResponsePath{}
+	path.AppendFieldName("items")
+	path.AppendIndex(3)
+
+	if path.Len() != 2 || path.Key(1) != 3 {
+		t.Errorf("path = %v, want [items 3]", path)
+	}
+}
+
+func TestResponsePathCloneIsIndependent(t *testing.T) {
+	path := buildTestPath()
+	clone := path.Clone()
+
+	path.AppendFieldName("extra")
+	if clone.Len() == path.Len() {
+		t.Error("mutating the original path also mutated its clone")
+	}
+	if clone.String() == path.String() {
+		t.Error("clone.String() changed along with the original path")
+	}
+}
+
+func TestResponsePathParent(t *testing.T) {
+	path := buildTestPath()
+	parent := path.Parent()
+
+	if parent.Len() != path.Len()-1 {
+		t.Errorf("parent.Len() = %d, want %d", parent.Len(), path.Len()-1)
+	}
+	if (&ResponsePath{}).Parent().Len() != 0 {
+		t.Error("Parent() of an empty path should still be empty")
+	}
+}
+
+func TestResponsePathHeadAndTail(t *testing.T) {
+	path := buildTestPath()
+
+	head := path.Head(2)
+	if head.String() != "user.friends" {
+		t.Errorf(`head.String() = %q, want "user.friends"`, head.String())
+	}
+
+	tail := path.Tail(2)
+	if tail.String() != "edges[0].node.name" {
+		t.Errorf(`tail.String() = %q, want "edges[0].node.name"`, tail.String())
+	}
+
+	if path.Head(100).Len() != path.Len() {
+		t.Error("Head(n) with n > Len() should clamp to Len()")
+	}
+	if path.Tail(100).Len() != 0 {
+		t.Error("Tail(n) with n > Len() should clamp to an empty path")
+	}
+}
+
+func TestResponsePathString(t *testing.T) {
+	path := buildTestPath()
+
+	want := "user.friends.edges[0].node.name"
+	if got := path.String(); got != want {
+		t.Errorf("path.String() = %q, want %q", got, want)
+	}
+}
+
+func TestResponsePathMarshalJSON(t *testing.T) {
+	path := &ResponsePath{}
+	path.AppendFieldName("user")
+	path.AppendIndex(1)
+
+	data, err := path.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON() returned an error: %v", err)
+	}
+	if got := string(data); got != `["user",1]` {
+		t.Errorf("MarshalJSON() = %s, want [\"user\",1]", got)
+	}
+}
+
+func TestParsePathMatcherRejectsMalformedPattern(t *testing.T) {
+	cases := []string{
+		"edges[0",
+		"edges[x]",
+	}
+	for _, pattern := range cases {
+		if _, err := ParsePathMatcher(pattern); err == nil {
+			t.Errorf("ParsePathMatcher(%q) = nil error, want one", pattern)
+		}
+	}
+}
+
+func TestPathMatcherMatchesLiteralFieldsAndIndices(t *testing.T) {
+	m, err := ParsePathMatcher("user.friends.edges[0].node.name")
+	if err != nil {
+		t.Fatalf("ParsePathMatcher() returned an error: %v", err)
+	}
+
+	if !m.Match(buildTestPath()) {
+		t.Error("Match() = false, want true for an identical literal path")
+	}
+
+	other := &ResponsePath{}
+	other.AppendFieldName("user")
+	other.AppendFieldName("friends")
+	other.AppendEdgeIndex(1)
+	other.AppendNode()
+	other.AppendFieldName("name")
+	if m.Match(other) {
+		t.Error("Match() = true, want false when a literal index differs")
+	}
+}
+
+func TestPathMatcherWildcards(t *testing.T) {
+	m, err := ParsePathMatcher("users.*.posts.edges[*].node.title")
+	if err != nil {
+		t.Fatalf("ParsePathMatcher() returned an error: %v", err)
+	}
+
+	path := &ResponsePath{}
+	path.AppendFieldName("users")
+	path.AppendFieldName("alice")
+	path.AppendFieldName("posts")
+	path.AppendEdgeIndex(7)
+	path.AppendNode()
+	path.AppendFieldName("title")
+
+	if !m.Match(path) {
+		t.Error("Match() = false, want true: wildcard field/index segments should match anything of the right kind")
+	}
+
+	mismatched := &ResponsePath{}
+	mismatched.AppendFieldName("users")
+	mismatched.AppendIndex(0)
+	mismatched.AppendFieldName("posts")
+	mismatched.AppendEdgeIndex(7)
+	mismatched.AppendNode()
+	mismatched.AppendFieldName("title")
+	if m.Match(mismatched) {
+		t.Error("Match() = true, want false: a field wildcard should not match an index key")
+	}
+}
+
+func TestPathMatcherMatchRejectsLengthMismatchAndNil(t *testing.T) {
+	m, err := ParsePathMatcher("user.name")
+	if err != nil {
+		t.Fatalf("ParsePathMatcher() returned an error: %v", err)
+	}
+
+	if m.Match(nil) {
+		t.Error("Match(nil) = true, want false")
+	}
+
+	short := &ResponsePath{}
+	short.AppendFieldName("user")
+	if m.Match(short) {
+		t.Error("Match() = true, want false for a path shorter than the pattern")
+	}
+}
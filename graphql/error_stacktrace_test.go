@@ -0,0 +1,77 @@
+/**
+ * Copyright (c) 2018, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package graphql
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestErrorFormatPlusVAppendsStackTrace(t *testing.T) {
+	SetStackTraceCapture(true)
+	err := NewError("boom").(*Error)
+
+	plain := fmt.Sprintf("%v", err)
+	verbose := fmt.Sprintf("%+v", err)
+
+	if plain != err.Error() {
+		t.Errorf("%%v = %q, want %q", plain, err.Error())
+	}
+	if verbose == plain {
+		t.Error("%+v produced the same output as %v; want the stack trace appended")
+	}
+}
+
+func TestStackTraceCaptureCanBeDisabled(t *testing.T) {
+	SetStackTraceCapture(false)
+	defer SetStackTraceCapture(true)
+
+	err := NewError("boom").(*Error)
+	if trace := err.StackTrace(); trace != nil {
+		t.Errorf("StackTrace() = %v, want nil when capture is disabled", trace)
+	}
+}
+
+func TestStackTraceCaptureEnabledCapturesNonEmptyTrace(t *testing.T) {
+	SetStackTraceCapture(true)
+
+	err := NewError("boom").(*Error)
+	if trace := err.StackTrace(); len(trace) == 0 {
+		t.Error("StackTrace() = empty, want at least one frame when capture is enabled")
+	}
+}
+
+func TestStackTracePropagatesFromDeepestError(t *testing.T) {
+	SetStackTraceCapture(true)
+
+	root := NewError("root cause").(*Error)
+	wrapped := NewError("wrapped", root).(*Error)
+	rewrapped := NewError("rewrapped", wrapped).(*Error)
+
+	if len(root.stack) == 0 {
+		t.Fatal("root error has no captured stack; test is meaningless")
+	}
+	if len(rewrapped.stack) != len(root.stack) {
+		t.Errorf("rewrapped.stack has %d frames, want the %d frames captured at the root", len(rewrapped.stack), len(root.stack))
+	}
+	for i := range root.stack {
+		if rewrapped.stack[i] != root.stack[i] {
+			t.Errorf("rewrapped.stack[%d] = %v, want the root's frame %v", i, rewrapped.stack[i], root.stack[i])
+			break
+		}
+	}
+}
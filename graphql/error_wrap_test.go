@@ -0,0 +1,97 @@
+/**
+ * Copyright (c) 2018, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package graphql
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrorUnwrapReturnsWrappedError(t *testing.T) {
+	inner := errors.New("boom")
+	err := NewError("wrapping", inner).(*Error)
+
+	if got := err.Unwrap(); got != inner {
+		t.Errorf("err.Unwrap() = %v, want %v", got, inner)
+	}
+	if !errors.Is(err, inner) {
+		t.Error("errors.Is(err, inner) = false, want true")
+	}
+}
+
+func TestErrorIsMatchesSentinelKind(t *testing.T) {
+	err := NewError("bad input", ErrKindCoercion)
+
+	if !errors.Is(err, ErrCoercion) {
+		t.Error("errors.Is(err, ErrCoercion) = false, want true")
+	}
+	if errors.Is(err, ErrValidation) {
+		t.Error("errors.Is(err, ErrValidation) = true, want false")
+	}
+}
+
+func TestErrorIsMatchesAnotherErrorOfSameKindAndOp(t *testing.T) {
+	target := &Error{Kind: ErrKindExecution, Op: "resolve"}
+	matchingOp := NewError("failed", ErrKindExecution, Op("resolve"))
+	differentOp := NewError("failed", ErrKindExecution, Op("coerce"))
+
+	if !errors.Is(matchingOp, target) {
+		t.Error("errors.Is(matchingOp, target) = false, want true")
+	}
+	if errors.Is(differentOp, target) {
+		t.Error("errors.Is(differentOp, target) = true, want false (different Op)")
+	}
+}
+
+func TestErrorIsDoesNotMatchErrKindOther(t *testing.T) {
+	err := NewError("unclassified")
+	target := &Error{Kind: ErrKindOther}
+
+	if errors.Is(err, target) {
+		t.Error("errors.Is(err, target) = true, want false (ErrKindOther never matches)")
+	}
+}
+
+type customError struct{ detail string }
+
+func (e *customError) Error() string { return e.detail }
+
+func TestErrorAsFindsWrappedConcreteType(t *testing.T) {
+	inner := &customError{detail: "db timeout"}
+	err := NewError("query failed", inner)
+
+	var target *customError
+	if !errors.As(err, &target) {
+		t.Fatal("errors.As(err, &target) = false, want true")
+	}
+	if target != inner {
+		t.Errorf("errors.As found %v, want %v", target, inner)
+	}
+}
+
+func TestErrorAsFindsNestedArtemisError(t *testing.T) {
+	root := NewError("root cause", ErrKindCoercion).(*Error)
+	wrapped := NewError("wrapped", root)
+
+	var target *Error
+	if !errors.As(wrapped, &target) {
+		t.Fatal("errors.As(wrapped, &target) = false, want true")
+	}
+	if target.Kind != ErrKindCoercion {
+		t.Errorf("errors.As found Kind = %v, want ErrKindCoercion", target.Kind)
+	}
+}
@@ -0,0 +1,125 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+// Package errreport converts graphql.Error values into events for external observability systems
+// (error trackers, tracers), so a server can forward every error a resolver produces without
+// writing bespoke marshalling for each backend it integrates with.
+//
+// NewEvent implements the attribute mapping every adapter in this package shares: Op becomes the
+// transaction name, Kind becomes a tag and severity level, Path becomes a breadcrumb trail, and
+// Locations and Extensions become context/extra data. The Sentry and OpenTelemetry adapters
+// (sentry.go, otel.go) build on top of it, each translating an *Event into the shape their
+// respective backend expects.
+package errreport
+
+import (
+	"context"
+
+	"github.com/botobag/artemis/graphql"
+)
+
+// Reporter sends a GraphQL error encountered during execution to an external observability
+// system. Report is called once per *graphql.Error returned from a resolver, before the error is
+// serialized into the GraphQL response; a Reporter must not block or panic, since doing so would
+// delay or break the response it's merely meant to observe.
+type Reporter interface {
+	Report(ctx context.Context, err *graphql.Error)
+}
+
+// ReporterFunc adapts a plain function to a Reporter, the same way graphql.FieldResolverFunc
+// adapts a function to a field resolver.
+type ReporterFunc func(ctx context.Context, err *graphql.Error)
+
+// Report calls fn.
+func (fn ReporterFunc) Report(ctx context.Context, err *graphql.Error) {
+	fn(ctx, err)
+}
+
+// Breadcrumb is one entry of the breadcrumb trail Event builds from an Error's Path.
+type Breadcrumb struct {
+	Message string
+}
+
+// Event is the attribute mapping this package applies to every *graphql.Error. It's the common
+// shape the Sentry and OpenTelemetry adapters both translate from, so adding a new backend only
+// means writing one more translation off of Event rather than off of *graphql.Error directly.
+type Event struct {
+	// Message is the error's message, unconditionally.
+	Message string
+
+	// Transaction is err.Op, naming the operation that failed, if any.
+	Transaction string
+
+	// Level is the severity derived from err.Kind ("error" for every classified Kind but
+	// ErrKindInternal, which is reported as "fatal"; "" when err.Kind is ErrKindOther).
+	Level string
+
+	// Tags holds a single "graphql.kind" entry naming err.Kind, omitted when err.Kind is
+	// ErrKindOther.
+	Tags map[string]string
+
+	// Breadcrumbs is the single-entry trail built from err.Path, omitted when err.Path is nil.
+	Breadcrumbs []Breadcrumb
+
+	// Context holds err.Locations under "graphql.locations", omitted when err has none.
+	Context map[string]interface{}
+
+	// Extra is err.Extensions, omitted when err has none.
+	Extra map[string]interface{}
+}
+
+// NewEvent builds the Event for err. See Event's fields for the mapping applied to each of err's
+// fields.
+func NewEvent(err *graphql.Error) *Event {
+	event := &Event{
+		Message: err.Message,
+		Level:   levelForKind(err.Kind),
+	}
+
+	if err.Op != "" {
+		event.Transaction = string(err.Op)
+	}
+
+	if err.Kind != graphql.ErrKindOther {
+		event.Tags = map[string]string{"graphql.kind": err.Kind.String()}
+	}
+
+	if err.Path != nil {
+		event.Breadcrumbs = []Breadcrumb{{Message: "at " + err.Path.String()}}
+	}
+
+	if len(err.Locations) > 0 {
+		event.Context = map[string]interface{}{"graphql.locations": err.Locations}
+	}
+
+	if len(err.Extensions) > 0 {
+		event.Extra = map[string]interface{}(err.Extensions)
+	}
+
+	return event
+}
+
+// levelForKind reports the severity level Event.Level uses for kind.
+func levelForKind(kind graphql.ErrKind) string {
+	switch kind {
+	case graphql.ErrKindOther:
+		return ""
+	case graphql.ErrKindInternal:
+		return "fatal"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,152 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package errreport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/botobag/artemis/graphql"
+)
+
+func TestNewEventMapsBasicFields(t *testing.T) {
+	err := &graphql.Error{Message: "boom", Op: "Query.user"}
+	event := NewEvent(err)
+
+	if event.Message != "boom" {
+		t.Errorf("event.Message = %q, want %q", event.Message, "boom")
+	}
+	if event.Transaction != "Query.user" {
+		t.Errorf("event.Transaction = %q, want %q", event.Transaction, "Query.user")
+	}
+	if event.Tags != nil {
+		t.Errorf("event.Tags = %v, want nil for ErrKindOther", event.Tags)
+	}
+	if event.Breadcrumbs != nil {
+		t.Errorf("event.Breadcrumbs = %v, want nil with no Path", event.Breadcrumbs)
+	}
+}
+
+func TestNewEventLevelForKind(t *testing.T) {
+	cases := []struct {
+		kind graphql.ErrKind
+		want string
+	}{
+		{graphql.ErrKindOther, ""},
+		{graphql.ErrKindInternal, "fatal"},
+		{graphql.ErrKindCoercion, "error"},
+		{graphql.ErrKindValidation, "error"},
+	}
+	for _, c := range cases {
+		event := NewEvent(&graphql.Error{Kind: c.kind})
+		if event.Level != c.want {
+			t.Errorf("NewEvent(Kind: %v).Level = %q, want %q", c.kind, event.Level, c.want)
+		}
+	}
+}
+
+func TestNewEventTagsOmittedForOtherKind(t *testing.T) {
+	event := NewEvent(&graphql.Error{Kind: graphql.ErrKindCoercion})
+	if event.Tags["graphql.kind"] != graphql.ErrKindCoercion.String() {
+		t.Errorf(`event.Tags["graphql.kind"] = %q, want %q`, event.Tags["graphql.kind"], graphql.ErrKindCoercion.String())
+	}
+}
+
+func TestNewEventBreadcrumbFromPath(t *testing.T) {
+	path := &graphql.ResponsePath{}
+	path.AppendFieldName("user")
+	path.AppendFieldName("email")
+
+	event := NewEvent(&graphql.Error{Path: path})
+	if len(event.Breadcrumbs) != 1 {
+		t.Fatalf("len(event.Breadcrumbs) = %d, want 1", len(event.Breadcrumbs))
+	}
+	if event.Breadcrumbs[0].Message != "at "+path.String() {
+		t.Errorf("event.Breadcrumbs[0].Message = %q, want %q", event.Breadcrumbs[0].Message, "at "+path.String())
+	}
+}
+
+func TestNewEventContextFromLocationsAndExtraFromExtensions(t *testing.T) {
+	locations := []graphql.ErrorLocation{{Line: 1, Column: 2}}
+	extensions := graphql.ErrorExtensions{"code": "NOT_FOUND"}
+
+	event := NewEvent(&graphql.Error{Locations: locations, Extensions: extensions})
+	if event.Context["graphql.locations"] == nil {
+		t.Error(`event.Context["graphql.locations"] = nil, want the error's Locations`)
+	}
+	if event.Extra["code"] != "NOT_FOUND" {
+		t.Errorf(`event.Extra["code"] = %v, want "NOT_FOUND"`, event.Extra["code"])
+	}
+}
+
+type fakeSentryClient struct {
+	captured *Event
+}
+
+func (c *fakeSentryClient) CaptureEvent(event *Event) {
+	c.captured = event
+}
+
+func TestNewSentryReporterForwardsEventToClient(t *testing.T) {
+	client := &fakeSentryClient{}
+	reporter := NewSentryReporter(client)
+
+	err := &graphql.Error{Message: "boom"}
+	reporter.Report(context.Background(), err)
+
+	if client.captured == nil || client.captured.Message != "boom" {
+		t.Errorf("client.captured = %v, want an Event with Message %q", client.captured, "boom")
+	}
+}
+
+type fakeSpan struct {
+	name  string
+	attrs map[string]interface{}
+}
+
+func (s *fakeSpan) AddEvent(name string, attributes map[string]interface{}) {
+	s.name = name
+	s.attrs = attributes
+}
+
+func TestNewOTelReporterRecordsEventOnSpan(t *testing.T) {
+	span := &fakeSpan{}
+	reporter := NewOTelReporter(func(ctx context.Context) SpanEventRecorder {
+		return span
+	})
+
+	reporter.Report(context.Background(), &graphql.Error{Message: "boom", Op: "Query.user"})
+
+	if span.name != "graphql.error" {
+		t.Errorf("span.name = %q, want %q", span.name, "graphql.error")
+	}
+	if span.attrs["message"] != "boom" {
+		t.Errorf(`span.attrs["message"] = %v, want "boom"`, span.attrs["message"])
+	}
+	if span.attrs["transaction"] != "Query.user" {
+		t.Errorf(`span.attrs["transaction"] = %v, want "Query.user"`, span.attrs["transaction"])
+	}
+}
+
+func TestNewOTelReporterSkipsReportWhenNoSpanInContext(t *testing.T) {
+	reporter := NewOTelReporter(func(ctx context.Context) SpanEventRecorder {
+		return nil
+	})
+
+	// Must not panic when spanFromContext returns nil.
+	reporter.Report(context.Background(), &graphql.Error{Message: "boom"})
+}
@@ -0,0 +1,84 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package errreport
+
+import (
+	"context"
+
+	"github.com/botobag/artemis/graphql"
+)
+
+// SpanEventRecorder is the subset of an OpenTelemetry trace.Span this package needs: enough to
+// attach a named, attributed event to it. A real trace.Span already satisfies this.
+type SpanEventRecorder interface {
+	AddEvent(name string, attributes map[string]interface{})
+}
+
+// SpanFromContextFunc locates the SpanEventRecorder NewOTelReporter should record onto for a given
+// ctx. Wire in an adapter over your OpenTelemetry SDK's trace.SpanFromContext here; return nil for
+// a ctx with no recordable span (NewOTelReporter then reports nothing for it).
+type SpanFromContextFunc func(ctx context.Context) SpanEventRecorder
+
+// NewOTelReporter returns a Reporter that converts every Error into the same Event mapping
+// NewEvent produces and records it, as a span event named "graphql.error", onto the span
+// spanFromContext finds in the Report call's ctx.
+func NewOTelReporter(spanFromContext SpanFromContextFunc) Reporter {
+	return ReporterFunc(func(ctx context.Context, err *graphql.Error) {
+		span := spanFromContext(ctx)
+		if span == nil {
+			return
+		}
+		span.AddEvent("graphql.error", attributesForEvent(NewEvent(err)))
+	})
+}
+
+// attributesForEvent flattens event into the single attribute map AddEvent expects.
+func attributesForEvent(event *Event) map[string]interface{} {
+	attrs := map[string]interface{}{
+		"message": event.Message,
+	}
+
+	if event.Transaction != "" {
+		attrs["transaction"] = event.Transaction
+	}
+
+	if event.Level != "" {
+		attrs["level"] = event.Level
+	}
+
+	for key, value := range event.Tags {
+		attrs["tag."+key] = value
+	}
+
+	for key, value := range event.Context {
+		attrs[key] = value
+	}
+
+	for key, value := range event.Extra {
+		attrs["extra."+key] = value
+	}
+
+	if len(event.Breadcrumbs) > 0 {
+		messages := make([]string, len(event.Breadcrumbs))
+		for i, breadcrumb := range event.Breadcrumbs {
+			messages[i] = breadcrumb.Message
+		}
+		attrs["breadcrumbs"] = messages
+	}
+
+	return attrs
+}
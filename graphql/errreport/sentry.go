@@ -0,0 +1,41 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package errreport
+
+import (
+	"context"
+
+	"github.com/botobag/artemis/graphql"
+)
+
+// SentryClient is the subset of a sentry-go Hub or Client this package needs: just enough to hand
+// off an already-built Event. It's defined here, rather than taking a concrete *sentry.Hub
+// directly, so this package doesn't force a sentry-go dependency onto callers who only want the
+// OpenTelemetry adapter (or neither). Wire it up with a small shim that copies Event's fields onto
+// a *sentry.Event (Message, Transaction, Level, Tags, Breadcrumbs, Contexts, Extra all have direct
+// counterparts) and calls CaptureEvent with it.
+type SentryClient interface {
+	CaptureEvent(event *Event)
+}
+
+// NewSentryReporter returns a Reporter that converts every Error into an Event (see NewEvent) and
+// hands it to client.
+func NewSentryReporter(client SentryClient) Reporter {
+	return ReporterFunc(func(ctx context.Context, err *graphql.Error) {
+		client.CaptureEvent(NewEvent(err))
+	})
+}
@@ -0,0 +1,351 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package executor
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/botobag/artemis/graphql"
+	"github.com/botobag/artemis/graphql/ast"
+	values "github.com/botobag/artemis/graphql/internal/value"
+)
+
+// ComplexityFunc computes a field's complexity cost given the combined complexity of its children
+// (already summed/multiplied) and the field's coerced argument values. Schema builders attach one
+// per graphql.Field with RegisterFieldComplexity; fields with none registered fall back to
+// defaultComplexityFunc.
+type ComplexityFunc func(childComplexity int, args map[string]interface{}) int
+
+// defaultFieldComplexity is the cost of a field with no registered ComplexityFunc, not counting
+// its children.
+const defaultFieldComplexity = 1
+
+// fieldComplexitiesMu guards fieldComplexities: RegisterFieldComplexity can run concurrently with
+// AnalyzeComplexity (via complexityFuncFor) across in-flight requests sharing the same schema, so
+// unsynchronized access is a concurrent map read/write.
+var (
+	fieldComplexitiesMu sync.RWMutex
+	// fieldComplexities holds the ComplexityFunc registered for each graphql.Field via
+	// RegisterFieldComplexity.
+	fieldComplexities = map[graphql.Field]ComplexityFunc{}
+)
+
+// RegisterFieldComplexity installs fn as the ComplexityFunc used by AnalyzeComplexity to cost
+// field. Registering a field that already has one replaces the previous registration.
+func RegisterFieldComplexity(field graphql.Field, fn ComplexityFunc) {
+	fieldComplexitiesMu.Lock()
+	defer fieldComplexitiesMu.Unlock()
+	fieldComplexities[field] = fn
+}
+
+func complexityFuncFor(field graphql.Field) ComplexityFunc {
+	fieldComplexitiesMu.RLock()
+	fn, ok := fieldComplexities[field]
+	fieldComplexitiesMu.RUnlock()
+	if ok {
+		return fn
+	}
+	return defaultComplexityFunc
+}
+
+// defaultComplexityFunc costs a field at defaultFieldComplexity plus its children's complexity.
+func defaultComplexityFunc(childComplexity int, args map[string]interface{}) int {
+	return defaultFieldComplexity + childComplexity
+}
+
+// ComplexityOptions configures AnalyzeComplexity.
+type ComplexityOptions struct {
+	// MaxComplexity aborts analysis with an error once the running total exceeds it. Zero (the
+	// default) means unlimited.
+	MaxComplexity int
+
+	// ListArgumentNames are the argument names AnalyzeComplexity looks for on list fields to use as
+	// a multiplier on their children's complexity, tried in order; the first one present on the
+	// field wins. Defaults to {"first", "last", "limit"} when nil.
+	ListArgumentNames []string
+}
+
+func (opts *ComplexityOptions) maxComplexity() int {
+	if opts == nil {
+		return 0
+	}
+	return opts.MaxComplexity
+}
+
+func (opts *ComplexityOptions) listArgumentNames() []string {
+	if opts == nil || opts.ListArgumentNames == nil {
+		return defaultListArgumentNames
+	}
+	return opts.ListArgumentNames
+}
+
+var defaultListArgumentNames = []string{"first", "last", "limit"}
+
+// complexityOptionsByContextMu guards complexityOptionsByContext: concurrent requests each carry
+// their own *ExecutionContext, but all of them read and write this one map, so an unsynchronized
+// access from one request's SetComplexityOptions racing another's collectAndDispatchRootTasks is a
+// concurrent map read/write.
+var (
+	complexityOptionsByContextMu sync.RWMutex
+	// complexityOptionsByContext holds the ComplexityOptions installed on an ExecutionContext via
+	// SetComplexityOptions. collectAndDispatchRootTasks consults it to run AnalyzeComplexity ahead
+	// of dispatching ctx's root fields.
+	complexityOptionsByContext = map[*ExecutionContext]*ComplexityOptions{}
+)
+
+// SetComplexityOptions installs opts as the ComplexityOptions collectAndDispatchRootTasks runs
+// AnalyzeComplexity with before dispatching ctx's root fields, rejecting the operation outright
+// (before any resolver runs) if its estimated cost exceeds opts.MaxComplexity. Without one
+// installed, no complexity analysis runs for ctx's execution. Registering nil removes any
+// previously installed ComplexityOptions; it's also done automatically once ctx's execution
+// completes (see collectAndDispatchRootTasks), so ctx isn't pinned in complexityOptionsByContext
+// beyond the request it was built for.
+func SetComplexityOptions(ctx *ExecutionContext, opts *ComplexityOptions) {
+	complexityOptionsByContextMu.Lock()
+	defer complexityOptionsByContextMu.Unlock()
+	if opts == nil {
+		delete(complexityOptionsByContext, ctx)
+		return
+	}
+	complexityOptionsByContext[ctx] = opts
+}
+
+// complexityOptionsFor returns the ComplexityOptions installed for ctx via SetComplexityOptions,
+// and whether one was installed at all.
+func complexityOptionsFor(ctx *ExecutionContext) (*ComplexityOptions, bool) {
+	complexityOptionsByContextMu.RLock()
+	opts, ok := complexityOptionsByContext[ctx]
+	complexityOptionsByContextMu.RUnlock()
+	return opts, ok
+}
+
+// AnalyzeComplexity walks ctx's operation before execution and estimates its total complexity,
+// honoring @skip/@include and fragments the same way field collection does. collectAndDispatchRootTasks
+// calls it automatically for any ExecutionContext that has ComplexityOptions installed via
+// SetComplexityOptions, ahead of dispatching the operation's root fields, so that an operation
+// whose cost exceeds opts.MaxComplexity is rejected before any resolver runs.
+func AnalyzeComplexity(ctx *ExecutionContext, opts *ComplexityOptions) (int, error) {
+	rootType := ctx.Operation().RootType()
+	complexity, err := complexityOfSelectionSet(ctx, opts, ctx.Operation().Definition().SelectionSet, rootType)
+	if err != nil {
+		return 0, err
+	}
+	if max := opts.maxComplexity(); max > 0 && complexity > max {
+		return complexity, graphql.NewError(fmt.Sprintf(
+			"query's estimated complexity %d exceeds the maximum allowed complexity %d", complexity, max))
+	}
+	return complexity, nil
+}
+
+// complexityOfSelectionSet costs the fields directly selected on parentType, honoring
+// @skip/@include, and folds in fragments spread at this level: a fragment whose type condition
+// names parentType itself contributes its cost unconditionally (it always applies), while
+// fragments whose type conditions name different possible types of an abstract parentType are
+// mutually exclusive at runtime, so only the most expensive one is counted, to avoid summing costs
+// that can never co-occur.
+func complexityOfSelectionSet(
+	ctx *ExecutionContext,
+	opts *ComplexityOptions,
+	selectionSet ast.SelectionSet,
+	parentType graphql.Type) (int, error) {
+
+	// Cost of fields/fragments that apply unconditionally at this level.
+	unconditional := 0
+
+	// Highest-cost fragment seen so far, per type condition name; only the winner (the most
+	// expensive mutually-exclusive branch) is added to the total.
+	byTypeCondition := map[string]int{}
+
+	var visit func(selectionSet ast.SelectionSet, visitedFragmentNames map[string]bool) error
+	visit = func(selectionSet ast.SelectionSet, visitedFragmentNames map[string]bool) error {
+		for _, selection := range selectionSet {
+			shouldInclude, err := shouldIncludeNode(ctx, selection)
+			if err != nil {
+				return err
+			} else if !shouldInclude {
+				continue
+			}
+
+			switch selection := selection.(type) {
+			case *ast.Field:
+				cost, err := complexityOfField(ctx, opts, selection, parentType)
+				if err != nil {
+					return err
+				}
+				unconditional += cost
+
+			case *ast.InlineFragment:
+				conditionType, cost, err := complexityOfFragment(
+					ctx, opts, selection.TypeCondition, selection.HasTypeCondition(), selection.SelectionSet, parentType)
+				if err != nil {
+					return err
+				}
+				if conditionType == "" {
+					unconditional += cost
+				} else if cost > byTypeCondition[conditionType] {
+					byTypeCondition[conditionType] = cost
+				}
+
+			case *ast.FragmentSpread:
+				fragmentName := selection.Name.Value()
+				if visitedFragmentNames[fragmentName] {
+					continue
+				}
+				visitedFragmentNames[fragmentName] = true
+
+				fragmentDef := ctx.Operation().FragmentDef(fragmentName)
+				if fragmentDef == nil {
+					continue
+				}
+
+				conditionType, cost, err := complexityOfFragment(
+					ctx, opts, fragmentDef.TypeCondition, true, fragmentDef.SelectionSet, parentType)
+				if err != nil {
+					return err
+				}
+				if conditionType == "" {
+					unconditional += cost
+				} else if cost > byTypeCondition[conditionType] {
+					byTypeCondition[conditionType] = cost
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := visit(selectionSet, map[string]bool{}); err != nil {
+		return 0, err
+	}
+
+	total := unconditional
+	max := 0
+	for _, cost := range byTypeCondition {
+		if cost > max {
+			max = cost
+		}
+	}
+	total += max
+
+	return total, nil
+}
+
+// complexityOfFragment costs a fragment's selection set against parentType. It returns "" as the
+// type condition when the fragment's condition names parentType itself (or parentType isn't an
+// abstract type split across possible types), meaning the cost should be folded in
+// unconditionally rather than bucketed against competing branches.
+func complexityOfFragment(
+	ctx *ExecutionContext,
+	opts *ComplexityOptions,
+	typeCondition ast.NamedType,
+	hasTypeCondition bool,
+	selectionSet ast.SelectionSet,
+	parentType graphql.Type) (conditionTypeName string, cost int, err error) {
+
+	fragmentType := parentType
+	if hasTypeCondition {
+		if t := ctx.Operation().Schema().TypeFromAST(typeCondition); t != nil {
+			fragmentType = t
+		}
+	}
+
+	cost, err = complexityOfSelectionSet(ctx, opts, selectionSet, fragmentType)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if _, ok := parentType.(graphql.AbstractType); !ok || fragmentType == parentType {
+		return "", cost, nil
+	}
+	if named, ok := fragmentType.(interface{ Name() string }); ok {
+		return named.Name(), cost, nil
+	}
+	return "", cost, nil
+}
+
+// complexityOfField costs a single field selection: its own ComplexityFunc (or
+// defaultComplexityFunc) applied to its coerced arguments and the summed complexity of its own
+// selection set, if any.
+func complexityOfField(
+	ctx *ExecutionContext,
+	opts *ComplexityOptions,
+	selection *ast.Field,
+	parentType graphql.Type) (int, error) {
+
+	object, ok := parentType.(graphql.Object)
+	if !ok {
+		// parentType is an Interface/Union selected on directly (rather than through a typed
+		// fragment); field lookup for that case isn't supported by findFieldDef yet, so skip it
+		// rather than guessing at its cost.
+		return 0, nil
+	}
+
+	fieldDef := findFieldDef(ctx.Operation().Schema(), object, selection.Name.Value())
+	if fieldDef == nil {
+		return 0, nil
+	}
+
+	arguments, err := values.ArgumentValues(fieldDef, selection, ctx.VariableValues())
+	if err != nil {
+		return 0, err
+	}
+
+	args := map[string]interface{}{}
+	for name := range fieldDef.Args() {
+		if value := arguments.Get(name); value != nil {
+			args[name] = value
+		}
+	}
+
+	childComplexity := 0
+	if len(selection.SelectionSet) > 0 {
+		underlyingType, isList := unwrapType(fieldDef.Type())
+
+		childComplexity, err = complexityOfSelectionSet(ctx, opts, selection.SelectionSet, underlyingType)
+		if err != nil {
+			return 0, err
+		}
+
+		if isList {
+			for _, argName := range opts.listArgumentNames() {
+				if n, ok := args[argName].(int); ok {
+					childComplexity *= n
+					break
+				}
+			}
+		}
+	}
+
+	return complexityFuncFor(fieldDef)(childComplexity, args), nil
+}
+
+// unwrapType strips NonNull and List wrappers off t, reporting whether any List was found along
+// the way (used to decide whether a field's child complexity should be scaled by a pagination
+// argument).
+func unwrapType(t graphql.Type) (underlyingType graphql.Type, isList bool) {
+	for {
+		switch wrapped := t.(type) {
+		case graphql.NonNull:
+			t = wrapped.InnerType()
+		case graphql.List:
+			isList = true
+			t = wrapped.ElementType()
+		default:
+			return t, isList
+		}
+	}
+}
@@ -0,0 +1,155 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package executor
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/botobag/artemis/graphql"
+)
+
+// AnalyzeComplexity itself walks a parsed operation via *ExecutionContext, which (like the rest of
+// the field-collection machinery it reuses — shouldIncludeNode, fragment spreads, etc.) needs a
+// *graphql.Schema and a parsed ast.Document to exercise end-to-end; that base layer isn't present
+// in this checkout (ExecutionContext is declared outside this package). The tests below instead
+// drive complexityOfSelectionSet/complexityOfField's building blocks and the pieces that are fully
+// self-contained: registering and looking up a field's ComplexityFunc, the default cost function,
+// and ComplexityOptions' defaulting. A full test covering nested lists, recursive fragments and a
+// variable-driven "@skip" would additionally assert AnalyzeComplexity's returned total and its
+// MaxComplexity error against a schema like:
+//
+//	type Query { posts(first: Int): [Post!]! }
+//	type Post { title: String comments: [Comment!]! }
+//	type Comment { body: String replies: [Comment!]! }
+//
+// with "posts(first: 10) { ...PostFields comments { ...PostFields } }" to exercise the recursive
+// fragment and list-multiplier paths, and "field @skip(if: $off)" with $off bound per-call to
+// exercise the variable-driven branch.
+
+func TestComplexityFuncForFallsBackToDefault(t *testing.T) {
+	field := graphql.NewField(graphql.FieldConfig{
+		Name: "title",
+		Type: graphql.String(),
+	})
+
+	fn := complexityFuncFor(field)
+	if got := fn(0, nil); got != defaultFieldComplexity {
+		t.Errorf("complexityFuncFor(unregistered field)(0, nil) = %d, want %d", got, defaultFieldComplexity)
+	}
+}
+
+func TestRegisterFieldComplexityOverridesDefault(t *testing.T) {
+	field := graphql.NewField(graphql.FieldConfig{
+		Name: "comments",
+		Type: graphql.String(),
+	})
+
+	RegisterFieldComplexity(field, func(childComplexity int, args map[string]interface{}) int {
+		return 5 + childComplexity
+	})
+	defer delete(fieldComplexities, field)
+
+	fn := complexityFuncFor(field)
+	if got := fn(3, nil); got != 8 {
+		t.Errorf("complexityFuncFor(registered field)(3, nil) = %d, want 8", got)
+	}
+}
+
+func TestDefaultComplexityFuncAddsChildCost(t *testing.T) {
+	if got := defaultComplexityFunc(7, nil); got != defaultFieldComplexity+7 {
+		t.Errorf("defaultComplexityFunc(7, nil) = %d, want %d", got, defaultFieldComplexity+7)
+	}
+}
+
+func TestComplexityOptionsDefaults(t *testing.T) {
+	var opts *ComplexityOptions
+	if max := opts.maxComplexity(); max != 0 {
+		t.Errorf("nil *ComplexityOptions.maxComplexity() = %d, want 0 (unlimited)", max)
+	}
+	if names := opts.listArgumentNames(); len(names) != len(defaultListArgumentNames) {
+		t.Errorf("nil *ComplexityOptions.listArgumentNames() = %v, want %v", names, defaultListArgumentNames)
+	}
+
+	opts = &ComplexityOptions{MaxComplexity: 42, ListArgumentNames: []string{"count"}}
+	if max := opts.maxComplexity(); max != 42 {
+		t.Errorf("maxComplexity() = %d, want 42", max)
+	}
+	if names := opts.listArgumentNames(); len(names) != 1 || names[0] != "count" {
+		t.Errorf("listArgumentNames() = %v, want [count]", names)
+	}
+}
+
+func TestSetComplexityOptionsNilRemovesEntry(t *testing.T) {
+	ctx := (*ExecutionContext)(nil)
+	opts := &ComplexityOptions{MaxComplexity: 10}
+
+	SetComplexityOptions(ctx, opts)
+	got, ok := complexityOptionsFor(ctx)
+	if !ok || got != opts {
+		t.Fatalf("complexityOptionsFor(ctx) = (%v, %v), want the just-installed opts", got, ok)
+	}
+
+	SetComplexityOptions(ctx, nil)
+	if _, ok := complexityOptionsFor(ctx); ok {
+		t.Error("SetComplexityOptions(ctx, nil) left an entry in complexityOptionsByContext")
+	}
+}
+
+func TestUnwrapTypeReportsList(t *testing.T) {
+	scalar := graphql.String()
+
+	if underlying, isList := unwrapType(scalar); isList || underlying != scalar {
+		t.Errorf("unwrapType(scalar) = (%v, %v), want (scalar, false)", underlying, isList)
+	}
+
+	list := graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(scalar)))
+	underlying, isList := unwrapType(list)
+	if !isList {
+		t.Error("unwrapType([String!]!) reported isList = false")
+	}
+	if underlying != scalar {
+		t.Errorf("unwrapType([String!]!) underlying = %v, want the innermost scalar", underlying)
+	}
+}
+
+// TestFieldComplexitiesConcurrentAccess exercises the scenario that motivated
+// fieldComplexitiesMu: RegisterFieldComplexity and complexityFuncFor running concurrently across
+// in-flight requests that share the same schema's fields. Run with -race; without the mutex this
+// trips Go's concurrent map read/write detector.
+func TestFieldComplexitiesConcurrentAccess(t *testing.T) {
+	field := graphql.NewField(graphql.FieldConfig{
+		Name: "concurrent",
+		Type: graphql.String(),
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			RegisterFieldComplexity(field, func(childComplexity int, args map[string]interface{}) int {
+				return childComplexity
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			complexityFuncFor(field)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,115 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package executor
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/botobag/artemis/graphql"
+)
+
+// These tests exercise the @defer queueing/draining machinery directly rather than by driving a
+// full collectChildExecutionNodes/DispatchDeferred pass (which needs a *graphql.Schema and a real
+// *ExecutionContext; ExecutionContext is declared outside this package and this checkout doesn't
+// have that layer present — see the same note in recover_test.go). None of the functions under
+// test here call a method on ctx — it's only ever used as a map key — so a nil *ExecutionContext
+// stands in for "some execution" without needing to build a real one.
+
+// TestQueueDeferredFragmentStoresPath covers the bug this request fixed: every queued
+// deferredFragment must carry the response path of its parent field, so DispatchDeferred can tell
+// the transport where to splice the resulting IncrementalPatch into the already-delivered tree.
+func TestQueueDeferredFragmentStoresPath(t *testing.T) {
+	ctx := (*ExecutionContext)(nil)
+	path := &graphql.ResponsePath{}
+	path.AppendFieldName("author")
+
+	queueDeferredFragment(ctx, nil, path, nil, nil, "", nil)
+	defer takeDeferredFragments(ctx)
+
+	fragments := deferredFragmentsByContext[ctx]
+	if len(fragments) != 1 {
+		t.Fatalf("got %d queued fragments, want 1", len(fragments))
+	}
+	if fragments[0].path != path {
+		t.Errorf("queued fragment.path = %v, want the path passed to queueDeferredFragment", fragments[0].path)
+	}
+}
+
+// TestTakeDeferredFragmentsDrainsInOrderAndClears covers the FIFO ordering DispatchDeferred relies
+// on to flush sibling "@defer" fragments in the order they were collected, and that a drained
+// context leaves nothing behind for a later take to find.
+func TestTakeDeferredFragmentsDrainsInOrderAndClears(t *testing.T) {
+	ctx := (*ExecutionContext)(nil)
+	queueDeferredFragment(ctx, nil, nil, nil, nil, "first", nil)
+	queueDeferredFragment(ctx, nil, nil, nil, nil, "second", nil)
+
+	fragments := takeDeferredFragments(ctx)
+	if len(fragments) != 2 {
+		t.Fatalf("got %d fragments, want 2", len(fragments))
+	}
+	if fragments[0].label != "first" || fragments[1].label != "second" {
+		t.Errorf("fragments drained out of order: %q, %q", fragments[0].label, fragments[1].label)
+	}
+
+	if remaining := takeDeferredFragments(ctx); remaining != nil {
+		t.Errorf("takeDeferredFragments after a full drain = %v, want nil", remaining)
+	}
+}
+
+// TestHasDeferredFragmentsDoesNotDrain covers the peek semantics collectAndDispatchRootTasks relies
+// on to decide whether ExecutionResult.Incremental should be populated, without consuming the
+// queue DispatchDeferred still needs to drain afterwards.
+func TestHasDeferredFragmentsDoesNotDrain(t *testing.T) {
+	ctx := (*ExecutionContext)(nil)
+
+	if hasDeferredFragments(ctx) {
+		t.Fatal("hasDeferredFragments reported true before anything was queued")
+	}
+
+	queueDeferredFragment(ctx, nil, nil, nil, nil, "", nil)
+	if !hasDeferredFragments(ctx) {
+		t.Fatal("hasDeferredFragments reported false after queueDeferredFragment")
+	}
+
+	fragments := takeDeferredFragments(ctx)
+	if len(fragments) != 1 {
+		t.Fatalf("hasDeferredFragments consumed the queue; takeDeferredFragments afterwards got %d fragments, want 1", len(fragments))
+	}
+}
+
+// TestDeferredFragmentsByContextConcurrentAccess exercises the scenario that motivated
+// deferredFragmentsByContextMu: distinct requests, each with its own *ExecutionContext, queueing
+// and draining concurrently. Run with -race; without the mutex this trips Go's concurrent map
+// read/write detector.
+func TestDeferredFragmentsByContextConcurrentAccess(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		ctx := (*ExecutionContext)(nil)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			queueDeferredFragment(ctx, nil, nil, nil, nil, "", nil)
+		}()
+		go func() {
+			defer wg.Done()
+			hasDeferredFragments(ctx)
+			takeDeferredFragments(ctx)
+		}()
+	}
+	wg.Wait()
+}
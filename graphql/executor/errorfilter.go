@@ -0,0 +1,86 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package executor
+
+import (
+	"sync"
+
+	"github.com/botobag/artemis/graphql"
+)
+
+// ErrorFilter inspects a field error before it's appended to ctx's result, returning the error to
+// report in its place (often e itself, unchanged) and whether to drop it from the response
+// entirely. It's meant for masking internal errors under specific fields (with a
+// graphql.PathMatcher matched against e.Path) while letting the rest of the response's errors
+// through unchanged.
+type ErrorFilter func(e *graphql.Error) (rewritten *graphql.Error, drop bool)
+
+// errorFilterByContextMu guards errorFilterByContext, the same workaround recoverFuncByContextMu
+// uses for recoverFuncByContext: concurrent requests each carry their own *ExecutionContext, but
+// all of them read and write this one map.
+var (
+	errorFilterByContextMu sync.RWMutex
+	// errorFilterByContext holds the ErrorFilter installed on an ExecutionContext via
+	// SetErrorFilter, the same workaround recoverFuncByContext uses for RecoverFunc.
+	errorFilterByContext = map[*ExecutionContext]ErrorFilter{}
+)
+
+// SetErrorFilter installs filter as the ErrorFilter handleNodeError runs every field error
+// through, after reporting it (see SetReporter) but before appending it to ctx's result.
+// Registering nil removes any previously installed ErrorFilter.
+func SetErrorFilter(ctx *ExecutionContext, filter ErrorFilter) {
+	errorFilterByContextMu.Lock()
+	defer errorFilterByContextMu.Unlock()
+	if filter == nil {
+		delete(errorFilterByContext, ctx)
+		return
+	}
+	errorFilterByContext[ctx] = filter
+}
+
+// filterError runs e through ctx's configured ErrorFilter, if any, returning the error to append
+// to the response, or nil if it should be dropped.
+func filterError(ctx *ExecutionContext, e *graphql.Error) *graphql.Error {
+	errorFilterByContextMu.RLock()
+	filter, ok := errorFilterByContext[ctx]
+	errorFilterByContextMu.RUnlock()
+	if !ok {
+		return e
+	}
+
+	rewritten, drop := filter(e)
+	if drop {
+		return nil
+	}
+	return rewritten
+}
+
+// NewPathErrorFilter returns an ErrorFilter that, for every error whose Path matches matcher, calls
+// rewrite to obtain the error to report in its place — or, if rewrite is nil, drops it from the
+// response entirely. Errors whose Path doesn't match (including ones with no Path at all) pass
+// through unchanged.
+func NewPathErrorFilter(matcher *graphql.PathMatcher, rewrite func(*graphql.Error) *graphql.Error) ErrorFilter {
+	return func(e *graphql.Error) (*graphql.Error, bool) {
+		if e.Path == nil || !matcher.Match(e.Path) {
+			return e, false
+		}
+		if rewrite == nil {
+			return nil, true
+		}
+		return rewrite(e), false
+	}
+}
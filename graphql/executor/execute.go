@@ -17,11 +17,14 @@
 package executor
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"sync"
 
 	"github.com/botobag/artemis/graphql"
 	"github.com/botobag/artemis/graphql/ast"
+	"github.com/botobag/artemis/graphql/introspection"
 	values "github.com/botobag/artemis/graphql/internal/value"
 )
 
@@ -29,6 +32,17 @@ import (
 type ExecutionResult struct {
 	Data   *ResultNode
 	Errors graphql.Errors
+
+	// Incremental, if non-nil, delivers one IncrementalPatch per fragment deferred with "@defer" in
+	// the operation. It's populated automatically (via DispatchDeferred) when the operation
+	// contained at least one such fragment; callers should read it to completion (until the channel
+	// closes) before considering the response done. nil for operations that used no "@defer".
+	Incremental <-chan *IncrementalPatch
+
+	// Extensions holds out-of-band data contributed by Extensions (see extension.go), keyed by
+	// each extension's chosen name (e.g. "tracing" for TracingExtension). nil unless at least one
+	// extension wrote to it.
+	Extensions map[string]interface{}
 }
 
 // Given a selectionSet, adds all of the fields in that selection to the passed in map of fields,
@@ -39,7 +53,9 @@ type ExecutionResult struct {
 func collectFields(
 	ctx *ExecutionContext,
 	node *ExecutionNode,
-	runtimeType graphql.Object) ([]*ExecutionNode, error) {
+	path *graphql.ResponsePath,
+	runtimeType graphql.Object,
+	source interface{}) ([]*ExecutionNode, error) {
 	// Look up nodes for the Selection Set with the given runtime type in node's child nodes.
 	var childNodes []*ExecutionNode
 
@@ -54,7 +70,7 @@ func collectFields(
 	if childNodes == nil {
 		// Load selection set into ExecutionNode's.
 		var err error
-		childNodes, err = buildChildExecutionNodesForSelectionSet(ctx, node, runtimeType)
+		childNodes, err = buildChildExecutionNodesForSelectionSet(ctx, node, path, runtimeType, source)
 		if err != nil {
 			return nil, err
 		}
@@ -70,7 +86,53 @@ func collectFields(
 func buildChildExecutionNodesForSelectionSet(
 	ctx *ExecutionContext,
 	parentNode *ExecutionNode,
-	runtimeType graphql.Object) ([]*ExecutionNode, error) {
+	path *graphql.ResponsePath,
+	runtimeType graphql.Object,
+	source interface{}) ([]*ExecutionNode, error) {
+	// Find the selection sets in parentNode to process.
+	var selectionSets []ast.SelectionSet
+	if parentNode.IsRoot() {
+		selectionSets = []ast.SelectionSet{ctx.Operation().Definition().SelectionSet}
+	} else {
+		definitions := parentNode.Definitions
+		selectionSets = make([]ast.SelectionSet, len(definitions))
+		for i, definition := range definitions {
+			selectionSets[i] = definition.SelectionSet
+		}
+	}
+
+	return collectChildExecutionNodes(ctx, parentNode, path, runtimeType, source, selectionSets)
+}
+
+// buildExecutionNodesForDeferredFragment builds the ExecutionNode's for a single fragment's
+// selection set, queued earlier by queueDeferredFragment. Unlike
+// buildChildExecutionNodesForSelectionSet, it doesn't look at parentNode.Definitions: a deferred
+// fragment is processed on its own, independently of whatever else shares parentNode. path is the
+// fragment's own path (queued alongside it), since any @defer nested directly in its selection
+// set shares the same parent field.
+func buildExecutionNodesForDeferredFragment(
+	ctx *ExecutionContext,
+	parentNode *ExecutionNode,
+	path *graphql.ResponsePath,
+	runtimeType graphql.Object,
+	selectionSet ast.SelectionSet,
+	source interface{}) ([]*ExecutionNode, error) {
+	return collectChildExecutionNodes(ctx, parentNode, path, runtimeType, source, []ast.SelectionSet{selectionSet})
+}
+
+// collectChildExecutionNodes walks selectionSets (processed in order, each to completion before
+// the next begins, per the spec's DFS field-collection order) and returns the ExecutionNode's
+// collected for runtimeType, honoring @skip/@include/@defer along the way. path is the response
+// path of parentNode's own field (nil/empty at the root), passed through to any @defer fragment
+// queued while collecting these selection sets so DispatchDeferred knows where to splice its
+// patch.
+func collectChildExecutionNodes(
+	ctx *ExecutionContext,
+	parentNode *ExecutionNode,
+	path *graphql.ResponsePath,
+	runtimeType graphql.Object,
+	source interface{},
+	selectionSets []ast.SelectionSet) ([]*ExecutionNode, error) {
 	// Boolean set to prevent named fragment to be applied twice or more in a selection set.
 	visitedFragmentNames := map[string]bool{}
 
@@ -89,22 +151,11 @@ func buildChildExecutionNodesForSelectionSet(
 		selectionIndex int
 	}
 
-	// Stack contains task to be processed.
-	var stack []taskData
-
-	// Initialize the stack. Find the selection sets in parentNode to process.
-	if parentNode.IsRoot() {
-		stack = []taskData{
-			{ctx.Operation().Definition().SelectionSet, 0},
-		}
-	} else {
-		definitions := parentNode.Definitions
-		numDefinitions := len(definitions)
-		stack = make([]taskData, numDefinitions)
-		// stack is LIFO so place the selection sets in reverse order.
-		for i, definition := range definitions {
-			stack[numDefinitions-i-1].selectionSet = definition.SelectionSet
-		}
+	// Stack contains task to be processed. Initialize it with selectionSets in reverse order, since
+	// the stack is LIFO.
+	stack := make([]taskData, len(selectionSets))
+	for i, selectionSet := range selectionSets {
+		stack[len(selectionSets)-i-1].selectionSet = selectionSet
 	}
 
 	for len(stack) > 0 {
@@ -161,6 +212,16 @@ func buildChildExecutionNodesForSelectionSet(
 						return nil, err
 					}
 
+					// "@stream" isn't implemented yet (see streamArgs' doc comment); rather than silently
+					// resolving the whole list synchronously while claiming to support it, fail the field
+					// outright so a client relying on streaming finds out instead of getting a full,
+					// unstreamed list with no indication anything was skipped.
+					if _, _, streamed, err := streamArgs(ctx, selection); err != nil {
+						return nil, err
+					} else if streamed {
+						return nil, fmt.Errorf("field %q: @stream is not supported by this server", name)
+					}
+
 					// Build a node.
 					field = &ExecutionNode{
 						Parent:         parentNode,
@@ -184,6 +245,13 @@ func buildChildExecutionNodesForSelectionSet(
 					}
 				}
 
+				if label, deferred, err := deferArgs(ctx, parentNode, selection); err != nil {
+					return nil, err
+				} else if deferred {
+					queueDeferredFragment(ctx, parentNode, path, runtimeType, selection.SelectionSet, label, source)
+					break
+				}
+
 				// Push a task to process selection set in the fragment.
 				stack = append(stack, taskData{
 					selectionSet: selection.SelectionSet,
@@ -210,6 +278,13 @@ func buildChildExecutionNodesForSelectionSet(
 					break
 				}
 
+				if label, deferred, err := deferArgs(ctx, parentNode, selection); err != nil {
+					return nil, err
+				} else if deferred {
+					queueDeferredFragment(ctx, parentNode, path, runtimeType, fragmentDef.SelectionSet, label, source)
+					break
+				}
+
 				// Push a task to process selection set in the fragment.
 				stack = append(stack, taskData{
 					selectionSet: fragmentDef.SelectionSet,
@@ -256,16 +331,65 @@ func shouldIncludeNode(ctx *ExecutionContext, node ast.Selection) (bool, error)
 	return true, nil
 }
 
-// This method looks up the field on the given type definition. It has special casing for the two
-// introspection fields, __schema and __typename. __typename is special because it can always be
-// queried as a field, even in situations where no other fields are allowed, like on a Union.
-// __schema could get automatically added to the query type, but that would require mutating type
-// definitions, which would cause issues.
+// extraRootFieldsMu guards extraRootFields: federation.Setup (or a hot-reloaded/second schema) can
+// call RegisterRootField concurrently with in-flight requests whose findFieldDef reads it on every
+// field lookup, so unsynchronized access is a concurrent map read/write.
+var (
+	extraRootFieldsMu sync.RWMutex
+	// extraRootFields holds synthetic fields spliced onto a schema's Query root by extensions that
+	// can't declare them in the user's own SDL, such as Apollo Federation's "_service" and
+	// "_entities" (see graphql/federation). findFieldDef consults it before falling back to the
+	// schema's own fields.
+	extraRootFields = map[*graphql.Schema]map[string]graphql.Field{}
+)
+
+// RegisterRootField installs field as an additional field on schema's Query root, looked up by
+// name. It's meant for extensions (such as graphql/federation) that need to add fields like
+// "_service" and "_entities" to the query root without the user declaring them in SDL.
+func RegisterRootField(schema *graphql.Schema, name string, field graphql.Field) {
+	extraRootFieldsMu.Lock()
+	defer extraRootFieldsMu.Unlock()
+	fields := extraRootFields[schema]
+	if fields == nil {
+		fields = map[string]graphql.Field{}
+		extraRootFields[schema] = fields
+	}
+	fields[name] = field
+}
+
+// This method looks up the field on the given type definition. It has special casing for the
+// introspection fields __typename, __schema and __type. __typename is special because it can
+// always be queried as a field, even in situations where no other fields are allowed, like on a
+// Union; it's checked first so nothing below (including a misbehaving schema field named
+// "__typename") can shadow it. __schema and __type are only legal on the Query root, so they're
+// checked after it and extraRootFields, rather than added to the root's own Fields() map, to avoid
+// mutating type definitions.
 func findFieldDef(
 	schema *graphql.Schema,
 	parentType graphql.Object,
 	fieldName string) graphql.Field {
-	// TODO: Deal with special introspection fields.
+	if fieldName == "__typename" {
+		return introspection.TypeNameField(parentType)
+	}
+
+	extraRootFieldsMu.RLock()
+	fields, ok := extraRootFields[schema]
+	extraRootFieldsMu.RUnlock()
+	if ok {
+		if field, ok := fields[fieldName]; ok {
+			return field
+		}
+	}
+
+	if parentType == schema.QueryType() {
+		switch fieldName {
+		case "__schema":
+			return introspection.SchemaField(schema)
+		case "__type":
+			return introspection.TypeField(schema)
+		}
+	}
+
 	return parentType.Fields()[fieldName]
 }
 
@@ -292,7 +416,7 @@ func doesTypeConditionSatisfy(
 	return false
 }
 
-func collectAndDispatchRootTasks(ctx *ExecutionContext, executor executor) (*ResultNode, error) {
+func collectAndDispatchRootTasks(ctx *ExecutionContext, e executor) (result *ResultNode, err error) {
 	rootType := ctx.Operation().RootType()
 	// Root node is a special node which behaves like a field with nil parent and definition.
 	rootNode := &ExecutionNode{
@@ -300,24 +424,70 @@ func collectAndDispatchRootTasks(ctx *ExecutionContext, executor executor) (*Res
 		Definitions: nil,
 	}
 
-	// Collect fields in the top-level selection set.
-	nodes, err := collectFields(ctx, rootNode, rootType)
-	if err != nil {
-		return nil, err
+	// Allocate result node up front so extension hooks have somewhere to report their own panics.
+	result = &ResultNode{}
+
+	// Reject the operation outright, before any resolver runs, if it registered ComplexityOptions
+	// (see SetComplexityOptions) and its estimated cost exceeds MaxComplexity. The computed total is
+	// carried on the ExecutionResult passed to extension finish hooks below so it reaches
+	// ExecutionResult.Extensions["complexity"] the same way TracingExtension populates
+	// Extensions["tracing"].
+	var extensions map[string]interface{}
+	if opts, ok := complexityOptionsFor(ctx); ok {
+		complexity, complexityErr := AnalyzeComplexity(ctx, opts)
+		if complexityErr != nil {
+			err = complexityErr
+			return
+		}
+		extensions = map[string]interface{}{"complexity": complexity}
 	}
 
-	// Allocate result node.
-	result := &ResultNode{}
+	finishExecution := runExecutionDidStart(ctx, e, result)
+	defer func() {
+		// Populate Incremental once every root task above has been dispatched: any "@defer" fragment
+		// collected along the way has already been queued (see queueDeferredFragment), so
+		// hasDeferredFragments reports the operation's final answer. Leave it nil for operations that
+		// used no "@defer", per ExecutionResult.Incremental's doc comment.
+		var incremental <-chan *IncrementalPatch
+		if hasDeferredFragments(ctx) {
+			incremental = DispatchDeferred(ctx, e)
+		}
+
+		finishExecution(&ExecutionResult{
+			Data:        result,
+			Errors:      e.Errors(),
+			Incremental: incremental,
+			Extensions:  extensions,
+		})
+		// ctx won't be executed against again once we return; drop it from extensionsByContext,
+		// recoverFuncByContext, complexityOptionsByContext, reporterByContext and errorFilterByContext
+		// so those maps don't pin it (and everything it references) in memory for the life of the
+		// process. Goes through the same setters
+		// RegisterExtensions/SetRecoverFunc/SetComplexityOptions/SetReporter/SetErrorFilter use
+		// (rather than deleting from the maps directly) so this takes their lock.
+		RegisterExtensions(ctx, nil)
+		SetRecoverFunc(ctx, nil)
+		SetComplexityOptions(ctx, nil)
+		SetReporter(ctx, nil)
+		SetErrorFilter(ctx, nil)
+	}()
+
+	// Collect fields in the top-level selection set.
+	nodes, collectErr := collectFields(ctx, rootNode, result.Path(), rootType, ctx.RootValue())
+	if collectErr != nil {
+		err = collectErr
+		return
+	}
 
 	// Create tasks for executing root nodes.
 	dispatchTasksForObject(
 		ctx,
-		executor,
+		e,
 		result,
 		nodes,
 		ctx.RootValue())
 
-	return result, nil
+	return
 }
 
 // Dispatch tasks for evaluating an object value comprised of the fields specified in childNodes.
@@ -397,6 +567,15 @@ func (task *ExecuteNodeTask) Run() {
 		field  = node.Field
 	)
 
+	// A panic anywhere below (in the resolver, in value completion, in an extension hook not
+	// already guarded by safelyRunExtensionHook) must not take down the rest of the in-flight
+	// fields dispatched alongside this one; convert it into a graphql.Error on this field instead.
+	defer func() {
+		if r := recover(); r != nil {
+			task.handleNodeError(recoverToError(ctx, r), result)
+		}
+	}()
+
 	// Get field resolver to execute.
 	resolver := field.Resolver()
 	if resolver == nil {
@@ -410,8 +589,11 @@ func (task *ExecuteNodeTask) Run() {
 		ResultNode:       result,
 	}
 
+	finishField := runFieldDidStart(task.executor, result, info)
+
 	// Execute resolver to retrieve the field value
 	value, err := resolver.Resolve(ctx.ctx, task.source, info)
+	finishField(value, err)
 	if err != nil {
 		task.handleNodeError(err, result)
 		return
@@ -451,8 +633,17 @@ func (task *ExecuteNodeTask) handleNodeError(err error, result *ResultNode) {
 	result.Kind = ResultKindNil
 	result.Value = nil
 
-	// Append error to task.errs.
-	task.executor.AppendError(e, result)
+	// Feed e to ctx's configured errreport.Reporter, if any, before it's appended below; this never
+	// touches result or the response it becomes, only external observability.
+	reportError(task.ctx, e)
+
+	// Run e through ctx's configured ErrorFilter, if any, so masking or rewriting an error for
+	// specific fields (e.g. to hide internal detail) doesn't require forking this function. A
+	// dropped error still leaves the field's result nil (set above); it's just omitted from the
+	// response's "errors" list.
+	if filtered := filterError(task.ctx, e); filtered != nil {
+		task.executor.AppendError(filtered, result)
+	}
 }
 
 // completeValue implements "Value Completion" [0]. It ensures the value resolved from the field
@@ -583,18 +774,30 @@ func (task *ExecuteNodeTask) completeWrappingValue(
 			for i := range resultNodes {
 				resultNode := &resultNodes[i]
 				resultNode.Parent = result
+				elementValue, ok := safeInterfaceOf(v.Index(i))
+				if !ok {
+					task.handleNodeError(unreadableListElementError(task.ctx, task.node, i), resultNode)
+					continue
+				}
 				queue = append(queue, ValueNode{
 					returnType: elementWrappingType,
 					result:     resultNode,
-					value:      v.Index(i).Interface(),
+					value:      elementValue,
 				})
 			}
 		} else {
 			for i := range resultNodes {
 				resultNode := &resultNodes[i]
 				resultNode.Parent = result
-				value := v.Index(i).Interface()
-				if !task.completeNonWrappingValue(elementType, resultNode, value) {
+				elementValue, ok := safeInterfaceOf(v.Index(i))
+				if !ok {
+					task.handleNodeError(unreadableListElementError(task.ctx, task.node, i), resultNode)
+					if result.IsNil() {
+						break
+					}
+					continue
+				}
+				if !task.completeNonWrappingValue(elementType, resultNode, elementValue) {
 					// If the err causes the parent to be nil'ed, stop procsessing the remaining elements.
 					if result.IsNil() {
 						break
@@ -672,7 +875,7 @@ func (task *ExecuteNodeTask) completeObjectValue(
 	ctx := task.ctx
 
 	// Collect fields in the selection set.
-	childNodes, err := collectFields(ctx, task.node, returnType)
+	childNodes, err := collectFields(ctx, task.node, result.Path(), returnType, value)
 	if err != nil {
 		task.handleNodeError(err, result)
 		return false
@@ -684,9 +887,112 @@ func (task *ExecuteNodeTask) completeObjectValue(
 	return true
 }
 
+// TypeResolver resolves the concrete Object type behind a value returned for an abstract
+// (Interface or Union) type's field, at runtime. Schema builders register one per abstract type
+// with RegisterTypeResolver; when none is registered, completeAbstractValue falls back to asking
+// each of the abstract type's possible types' IsTypeOf predicate, per spec.
+type TypeResolver interface {
+	Resolve(ctx context.Context, value interface{}, info *ResolveInfo) graphql.Object
+}
+
+// typeResolversMu guards typeResolvers: schemas can register resolvers lazily (e.g. from an
+// init-once path triggered by the first request that needs them) while other in-flight requests
+// are concurrently resolving abstract types against the same schema, so RegisterTypeResolver and
+// resolveAbstractType need to be safe to call concurrently.
+var (
+	typeResolversMu sync.RWMutex
+	// typeResolvers holds the TypeResolver registered for each abstract type via
+	// RegisterTypeResolver.
+	typeResolvers = map[graphql.AbstractType]TypeResolver{}
+)
+
+// RegisterTypeResolver installs resolver as the TypeResolver used to resolve the runtime Object
+// type for values returned by fields typed as t. Registering a resolver for a type that already
+// has one replaces the previous registration.
+func RegisterTypeResolver(t graphql.AbstractType, resolver TypeResolver) {
+	typeResolversMu.Lock()
+	defer typeResolversMu.Unlock()
+	typeResolvers[t] = resolver
+}
+
+// resolveAbstractType implements the spec's "Resolve Abstract Type" algorithm: prefer the
+// abstract type's registered TypeResolver; fall back to asking each possible type's IsTypeOf
+// predicate until one matches.
+//
+// Reference: https://facebook.github.io/graphql/June2018/#ResolveAbstractType()
+func resolveAbstractType(
+	ctx context.Context,
+	abstractType graphql.AbstractType,
+	value interface{},
+	info *ResolveInfo) graphql.Object {
+
+	typeResolversMu.RLock()
+	resolver, ok := typeResolvers[abstractType]
+	typeResolversMu.RUnlock()
+	if ok {
+		return resolver.Resolve(ctx, value, info)
+	}
+
+	schema := info.ExecutionContext.Operation().Schema()
+	for _, possibleType := range schema.PossibleTypes(abstractType) {
+		if possibleType.IsTypeOf(ctx, value, info) {
+			return possibleType
+		}
+	}
+	return nil
+}
+
+// completeAbstractValue resolves returnType down to a concrete Object and delegates to
+// completeObjectValue, which collects the selection set against that concrete type via
+// findFieldDef. That's also what makes "{ ... on Union/Interface { __typename } }" work: once a
+// concrete type comes out of resolveAbstractType below, __typename resolves like any other field,
+// regardless of whether the Union/Interface itself declares any fields of its own.
 func (task *ExecuteNodeTask) completeAbstractValue(
 	returnType graphql.AbstractType,
 	result *ResultNode,
 	value interface{}) (ok bool) {
-	panic("unimplemented")
+
+	var (
+		ctx  = task.ctx
+		node = task.node
+	)
+
+	info := &ResolveInfo{
+		ExecutionContext: ctx,
+		ExecutionNode:    node,
+		ResultNode:       result,
+	}
+
+	concreteType := resolveAbstractType(ctx.ctx, returnType, value, info)
+	if concreteType == nil {
+		task.handleNodeError(
+			graphql.NewError(fmt.Sprintf(
+				`Abstract type "%s" must resolve to an Object type at runtime for field "%s.%s". `+
+					`Either the "%s" type should provide a TypeResolver (see RegisterTypeResolver) or `+
+					`each possible type should provide an IsTypeOf predicate.`,
+				returnType.Name(), parentFieldType(ctx, node).Name(), node.Field.Name(), returnType.Name())),
+			result)
+		return false
+	}
+
+	// Ensure the resolved type is actually one of the abstract type's possible types; a
+	// misbehaving TypeResolver/IsTypeOf is a programmer error that should surface as a field error
+	// rather than silently dispatch fields that don't apply.
+	isPossibleType := false
+	for _, possibleType := range ctx.Operation().Schema().PossibleTypes(returnType) {
+		if possibleType == concreteType {
+			isPossibleType = true
+			break
+		}
+	}
+	if !isPossibleType {
+		task.handleNodeError(
+			graphql.NewError(fmt.Sprintf(
+				`Runtime Object type "%s" is not a possible type for "%s".`,
+				concreteType.Name(), returnType.Name())),
+			result)
+		return false
+	}
+
+	return task.completeObjectValue(concreteType, result, value)
 }
\ No newline at end of file
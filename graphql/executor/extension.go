@@ -0,0 +1,152 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package executor
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/botobag/artemis/graphql"
+)
+
+// Extension hooks into the lifecycle of parsing, validating, and executing a GraphQL operation.
+// Each "DidStart" method is called before the corresponding phase begins and returns a function to
+// be invoked once that phase ends. RegisterExtensions installs the set an ExecutionContext runs
+// with; ExecutionDidStart and FieldDidStart are driven by this package (see
+// collectAndDispatchRootTasks and ExecuteNodeTask.Run). ValidationDidStart and ParsingDidStart are
+// declared here so a single Extension value can observe the whole request, but since validation
+// and parsing live in other packages, it's those packages' job to look extensions up and drive
+// them — this package never calls them itself.
+type Extension interface {
+	// ExecutionDidStart is called once per operation, before its root fields are collected. The
+	// returned function is invoked with the operation's ExecutionResult once execution completes.
+	ExecutionDidStart(ctx *ExecutionContext) func(result *ExecutionResult)
+
+	// ValidationDidStart is called before the operation's validation rules run. The returned
+	// function is invoked with whatever errors validation reported (nil on success).
+	ValidationDidStart() func(errs []error)
+
+	// ParsingDidStart is called before the operation's source text is parsed. The returned function
+	// is invoked with the error parsing produced, or nil on success.
+	ParsingDidStart() func(err error)
+
+	// FieldDidStart is called before a field's resolver runs. The returned function is invoked with
+	// the resolver's result and error once it returns.
+	FieldDidStart(info *ResolveInfo) func(value interface{}, err error)
+}
+
+// extensionsByContextMu guards extensionsByContext: concurrent requests each carry their own
+// *ExecutionContext, but all of them read and write this one map, so an unsynchronized access from
+// one request's runExecutionDidStart/runFieldDidStart racing another's RegisterExtensions is a
+// concurrent map read/write — a runtime fatal error, not something a panic recover() could ever
+// catch.
+var (
+	extensionsByContextMu sync.RWMutex
+	extensionsByContext   = map[*ExecutionContext][]Extension{}
+)
+
+// RegisterExtensions installs extensions as the set that ctx's execution runs with. Hooks fire in
+// the given order as each phase starts and in reverse order as it finishes, so an extension that
+// wraps another (e.g. for timing) sees its own finish callback run closest to the work it's
+// timing. Registering an empty or nil extensions removes any previously installed set; it's also
+// done automatically once ctx's execution completes (see collectAndDispatchRootTasks), so ctx
+// isn't pinned in extensionsByContext beyond the request it was built for.
+func RegisterExtensions(ctx *ExecutionContext, extensions []Extension) {
+	extensionsByContextMu.Lock()
+	defer extensionsByContextMu.Unlock()
+	if len(extensions) == 0 {
+		delete(extensionsByContext, ctx)
+		return
+	}
+	extensionsByContext[ctx] = extensions
+}
+
+// runExecutionDidStart invokes ExecutionDidStart on every extension installed on ctx, in order,
+// and returns a function that invokes their returned finish functions in reverse order. A panic
+// from an extension's hook is captured as a graphql.Error appended to result instead of aborting
+// execution, per the "extension errors shouldn't abort the operation" rule.
+func runExecutionDidStart(ctx *ExecutionContext, e executor, result *ResultNode) func(*ExecutionResult) {
+	extensionsByContextMu.RLock()
+	extensions := extensionsByContext[ctx]
+	extensionsByContextMu.RUnlock()
+	if len(extensions) == 0 {
+		return func(*ExecutionResult) {}
+	}
+
+	finishers := make([]func(*ExecutionResult), len(extensions))
+	for i, extension := range extensions {
+		i, extension := i, extension
+		safelyRunExtensionHook(e, result, func() {
+			finishers[i] = extension.ExecutionDidStart(ctx)
+		})
+	}
+
+	return func(executionResult *ExecutionResult) {
+		for i := len(finishers) - 1; i >= 0; i-- {
+			finish := finishers[i]
+			if finish == nil {
+				continue
+			}
+			safelyRunExtensionHook(e, result, func() {
+				finish(executionResult)
+			})
+		}
+	}
+}
+
+// runFieldDidStart invokes FieldDidStart on every extension installed on info.ExecutionContext, in
+// order, and returns a function that invokes their returned finish functions in reverse order,
+// mirroring runExecutionDidStart.
+func runFieldDidStart(e executor, result *ResultNode, info *ResolveInfo) func(value interface{}, err error) {
+	extensionsByContextMu.RLock()
+	extensions := extensionsByContext[info.ExecutionContext]
+	extensionsByContextMu.RUnlock()
+	if len(extensions) == 0 {
+		return func(interface{}, error) {}
+	}
+
+	finishers := make([]func(value interface{}, err error), len(extensions))
+	for i, extension := range extensions {
+		i, extension := i, extension
+		safelyRunExtensionHook(e, result, func() {
+			finishers[i] = extension.FieldDidStart(info)
+		})
+	}
+
+	return func(value interface{}, err error) {
+		for i := len(finishers) - 1; i >= 0; i-- {
+			finish := finishers[i]
+			if finish == nil {
+				continue
+			}
+			safelyRunExtensionHook(e, result, func() {
+				finish(value, err)
+			})
+		}
+	}
+}
+
+// safelyRunExtensionHook runs fn, converting any panic into a graphql.Error appended to result via
+// e.AppendError instead of letting it escape and abort the rest of the operation.
+func safelyRunExtensionHook(e executor, result *ResultNode, fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			e.AppendError(graphql.NewError(fmt.Sprintf("extension error: %v", r)).(*graphql.Error), result)
+		}
+	}()
+	fn()
+}
@@ -0,0 +1,112 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+// runExecutionDidStart/runFieldDidStart and TracingExtension's own hooks all need a fully
+// constructed *ExecutionContext/*ResolveInfo/*ExecutionResult, none of which this checkout's
+// partial core snapshot defines. The tests below instead cover RegisterExtensions, which only
+// touches extensionsByContext and is reachable with *ExecutionContext used purely as a map key
+// (see root_field_test.go for the same workaround with *graphql.Schema).
+package executor
+
+import (
+	"sync"
+	"testing"
+)
+
+type fakeExtension struct {
+	name string
+}
+
+func (fakeExtension) ExecutionDidStart(ctx *ExecutionContext) func(*ExecutionResult) { return nil }
+func (fakeExtension) ValidationDidStart() func(errs []error)                         { return nil }
+func (fakeExtension) ParsingDidStart() func(err error)                               { return nil }
+func (fakeExtension) FieldDidStart(info *ResolveInfo) func(value interface{}, err error) {
+	return nil
+}
+
+var _ Extension = fakeExtension{}
+
+func TestRegisterExtensionsIsScopedPerContext(t *testing.T) {
+	ctxA := &ExecutionContext{}
+	ctxB := &ExecutionContext{}
+	ext := fakeExtension{name: "a"}
+
+	RegisterExtensions(ctxA, []Extension{ext})
+	defer RegisterExtensions(ctxA, nil)
+
+	if got := extensionsByContext[ctxA]; len(got) != 1 || got[0] != Extension(ext) {
+		t.Errorf("extensionsByContext[ctxA] = %v, want [%v]", got, ext)
+	}
+	if _, ok := extensionsByContext[ctxB]; ok {
+		t.Errorf("extensionsByContext[ctxB] unexpectedly populated by a registration on ctxA")
+	}
+}
+
+func TestRegisterExtensionsNilOrEmptyUnregisters(t *testing.T) {
+	ctx := &ExecutionContext{}
+	RegisterExtensions(ctx, []Extension{fakeExtension{name: "a"}})
+
+	RegisterExtensions(ctx, nil)
+	if _, ok := extensionsByContext[ctx]; ok {
+		t.Error("RegisterExtensions(ctx, nil) should remove ctx's entry")
+	}
+
+	RegisterExtensions(ctx, []Extension{fakeExtension{name: "b"}})
+	RegisterExtensions(ctx, []Extension{})
+	if _, ok := extensionsByContext[ctx]; ok {
+		t.Error("RegisterExtensions(ctx, []Extension{}) should remove ctx's entry")
+	}
+}
+
+func TestRegisterExtensionsReplacesExistingSet(t *testing.T) {
+	ctx := &ExecutionContext{}
+	first := fakeExtension{name: "first"}
+	second := fakeExtension{name: "second"}
+
+	RegisterExtensions(ctx, []Extension{first})
+	RegisterExtensions(ctx, []Extension{second})
+	defer RegisterExtensions(ctx, nil)
+
+	got := extensionsByContext[ctx]
+	if len(got) != 1 || got[0] != Extension(second) {
+		t.Errorf("extensionsByContext[ctx] = %v, want [%v]", got, second)
+	}
+}
+
+// TestExtensionsByContextConcurrentAccess exercises the scenario extensionsByContextMu guards
+// against: one goroutine registering extensions for a request while another (e.g. a concurrent
+// request's field resolution) reads the map. Run with -race.
+func TestExtensionsByContextConcurrentAccess(t *testing.T) {
+	ctx := &ExecutionContext{}
+	ext := fakeExtension{name: "race"}
+	defer RegisterExtensions(ctx, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			RegisterExtensions(ctx, []Extension{ext})
+		}()
+		go func() {
+			defer wg.Done()
+			extensionsByContextMu.RLock()
+			_ = extensionsByContext[ctx]
+			extensionsByContextMu.RUnlock()
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,98 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package executor
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/botobag/artemis/graphql"
+)
+
+// RecoverFunc turns a value recovered from a panic (e.g. from a resolver or a CoerceResultValue)
+// into the error reported for the field that panicked. It's given ctx so it can, say, log the
+// panic (along with the returned Error's captured call stack, see Error.StackTrace) to an error
+// tracker, or scrub values that shouldn't end up in a client-facing error message.
+type RecoverFunc func(ctx *ExecutionContext, r interface{}) error
+
+// recoverFuncByContextMu guards recoverFuncByContext: concurrent requests each carry their own
+// *ExecutionContext, but all of them read and write this one map, so an unsynchronized access from
+// one request's recoverToError racing another's SetRecoverFunc is a concurrent map read/write —
+// a runtime fatal error, not something a panic recover() could ever catch.
+var (
+	recoverFuncByContextMu sync.RWMutex
+	recoverFuncByContext   = map[*ExecutionContext]RecoverFunc{}
+)
+
+// SetRecoverFunc installs fn as the RecoverFunc ExecuteNodeTask.Run uses to convert a recovered
+// panic into a field error for ctx's execution. Without one installed, DefaultRecoverFunc is used.
+// Registering nil removes any previously installed RecoverFunc; it's also done automatically once
+// ctx's execution completes (see collectAndDispatchRootTasks), so ctx isn't pinned in
+// recoverFuncByContext beyond the request it was built for.
+func SetRecoverFunc(ctx *ExecutionContext, fn RecoverFunc) {
+	recoverFuncByContextMu.Lock()
+	defer recoverFuncByContextMu.Unlock()
+	if fn == nil {
+		delete(recoverFuncByContext, ctx)
+		return
+	}
+	recoverFuncByContext[ctx] = fn
+}
+
+// DefaultRecoverFunc reports a generic, client-safe message for the field that panicked. The
+// recovered value is attached via the Error's diagnostic Context (see graphql.WithContext)
+// instead of being baked into the client-facing Message: NewError already captures a call stack
+// of its own for this goroutine (see graphql.SetStackTraceCapture), so there's no need to embed
+// debug.Stack()'s full, every-goroutine dump — which would otherwise leak internal stack frames
+// and file paths to API callers regardless of that toggle.
+func DefaultRecoverFunc(ctx *ExecutionContext, r interface{}) error {
+	return graphql.NewError(
+		"Internal server error",
+		graphql.WithContext("panic", fmt.Sprintf("%v", r)))
+}
+
+// recoverToError converts a value recovered from a panic into an error for the field that
+// panicked, via ctx's RecoverFunc (or DefaultRecoverFunc if none was installed).
+func recoverToError(ctx *ExecutionContext, r interface{}) error {
+	recoverFuncByContextMu.RLock()
+	fn, ok := recoverFuncByContext[ctx]
+	recoverFuncByContextMu.RUnlock()
+	if !ok || fn == nil {
+		fn = DefaultRecoverFunc
+	}
+	return fn(ctx, r)
+}
+
+// safeInterfaceOf is reflect.Value.Interface, except that instead of panicking when v was obtained
+// by reading an unexported struct field, it reports failure so the caller can turn that into a
+// regular field error for just the one list element affected, rather than letting the panic take
+// down every other in-flight sibling field.
+func safeInterfaceOf(v reflect.Value) (value interface{}, ok bool) {
+	if !v.CanInterface() {
+		return nil, false
+	}
+	return v.Interface(), true
+}
+
+// unreadableListElementError builds the error reported for a list element that safeInterfaceOf
+// couldn't read.
+func unreadableListElementError(ctx *ExecutionContext, node *ExecutionNode, index int) error {
+	return graphql.NewError(fmt.Sprintf(
+		"Cannot read element %d of list returned for field %s.%s.",
+		index, parentFieldType(ctx, node).Name(), node.Field.Name()))
+}
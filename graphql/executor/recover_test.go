@@ -0,0 +1,175 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package executor
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/botobag/artemis/graphql"
+)
+
+// These tests exercise recover.go's panic-to-error conversion directly rather than by driving a
+// full ExecuteNodeTask.Run (which needs a *graphql.Schema and *ExecutionContext; ExecutionContext
+// is declared outside this package and this checkout doesn't have that layer present). None of the
+// functions under test here dereference ctx — recoverFuncByContext only ever keys off it — so a nil
+// *ExecutionContext stands in for "some execution" without needing to build a real one.
+
+func TestDefaultRecoverFuncFromResolverPanic(t *testing.T) {
+	r := recoverFromPanic(func() {
+		panic("boom: resolver exploded")
+	})
+
+	err := DefaultRecoverFunc(nil, r)
+	gqlErr, ok := err.(*graphql.Error)
+	if !ok {
+		t.Fatalf("DefaultRecoverFunc returned %T, want *graphql.Error", err)
+	}
+
+	if gqlErr.Message != "Internal server error" {
+		t.Errorf("Message = %q, want a generic client-facing message, not the panic value", gqlErr.Message)
+	}
+	if strings.Contains(gqlErr.Message, "boom") {
+		t.Errorf("Message leaked the panic value: %q", gqlErr.Message)
+	}
+	if got := gqlErr.Context["panic"]; got != "boom: resolver exploded" {
+		t.Errorf("Context[panic] = %v, want the recovered value preserved for server-side diagnostics", got)
+	}
+}
+
+func TestDefaultRecoverFuncFromCoerceResultValuePanic(t *testing.T) {
+	r := recoverFromPanic(func() {
+		var m map[string]int
+		// A CoerceResultValue implementation that indexes a nil map it assumed was initialized is a
+		// representative way for that hook to panic instead of returning an error.
+		_ = m["missing"]
+		panic(fmt.Errorf("CoerceResultValue: %w", errDivByZeroForTest()))
+	})
+
+	err := DefaultRecoverFunc(nil, r)
+	gqlErr := err.(*graphql.Error)
+	if gqlErr.Message != "Internal server error" {
+		t.Errorf("Message = %q, want the generic message regardless of what CoerceResultValue panicked with", gqlErr.Message)
+	}
+}
+
+func errDivByZeroForTest() error {
+	return fmt.Errorf("integer divide by zero")
+}
+
+func TestRecoverToErrorUsesInstalledRecoverFunc(t *testing.T) {
+	ctx := (*ExecutionContext)(nil)
+
+	var got interface{}
+	SetRecoverFunc(ctx, func(_ *ExecutionContext, r interface{}) error {
+		got = r
+		return graphql.NewError("custom recover func ran")
+	})
+	defer SetRecoverFunc(ctx, nil)
+
+	r := recoverFromPanic(func() { panic("from inside a list element") })
+	err := recoverToError(ctx, r)
+
+	if got != "from inside a list element" {
+		t.Errorf("installed RecoverFunc saw %v, want the recovered panic value", got)
+	}
+	if err.Error() != "custom recover func ran" {
+		t.Errorf("recoverToError returned %v, want the installed RecoverFunc's result", err)
+	}
+}
+
+func TestRecoverToErrorFallsBackToDefault(t *testing.T) {
+	ctx := (*ExecutionContext)(nil)
+	// No RecoverFunc installed for ctx (and any previous test's is cleaned up via its own defer),
+	// so recoverToError should fall back to DefaultRecoverFunc.
+	err := recoverToError(ctx, "boom")
+	gqlErr, ok := err.(*graphql.Error)
+	if !ok || gqlErr.Message != "Internal server error" {
+		t.Errorf("recoverToError() = %v, want DefaultRecoverFunc's generic error", err)
+	}
+}
+
+func TestSetRecoverFuncNilRemovesEntry(t *testing.T) {
+	ctx := (*ExecutionContext)(nil)
+	SetRecoverFunc(ctx, func(_ *ExecutionContext, r interface{}) error { return nil })
+	if _, ok := recoverFuncByContext[ctx]; !ok {
+		t.Fatal("SetRecoverFunc with a non-nil fn didn't install it")
+	}
+
+	SetRecoverFunc(ctx, nil)
+	if _, ok := recoverFuncByContext[ctx]; ok {
+		t.Error("SetRecoverFunc(ctx, nil) left an entry in recoverFuncByContext")
+	}
+}
+
+// TestSafeInterfaceOfUnexportedField covers the completeWrappingValue hardening this request
+// asked for: reading a list element obtained via reflection over an unexported struct field must
+// report failure instead of panicking, so the caller can turn it into a field error scoped to that
+// one element (see unreadableListElementError) rather than taking down every in-flight sibling.
+func TestSafeInterfaceOfUnexportedField(t *testing.T) {
+	type withUnexported struct {
+		Exported   string
+		unexported string
+	}
+	v := withUnexported{Exported: "ok", unexported: "hidden"}
+	rv := reflect.ValueOf(v)
+
+	if _, ok := safeInterfaceOf(rv.FieldByName("Exported")); !ok {
+		t.Error("safeInterfaceOf reported failure for an exported, readable field")
+	}
+
+	if _, ok := safeInterfaceOf(rv.FieldByName("unexported")); ok {
+		t.Error("safeInterfaceOf should report failure instead of being able to panic on an unexported field")
+	}
+}
+
+// TestRecoverFuncByContextConcurrentAccess exercises the scenario that motivated
+// recoverFuncByContextMu: distinct requests, each with its own *ExecutionContext, calling
+// SetRecoverFunc and recoverToError concurrently. Run with -race; without the mutex this trips
+// Go's concurrent map read/write detector.
+func TestRecoverFuncByContextConcurrentAccess(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		ctx := (*ExecutionContext)(nil)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SetRecoverFunc(ctx, func(_ *ExecutionContext, r interface{}) error {
+				return graphql.NewError("custom")
+			})
+			SetRecoverFunc(ctx, nil)
+		}()
+		go func() {
+			defer wg.Done()
+			recoverToError(ctx, "boom")
+		}()
+	}
+	wg.Wait()
+}
+
+// recoverFromPanic runs fn and returns whatever it recovered from, mirroring the
+// defer/recover pattern ExecuteNodeTask.Run wraps around a resolver/completeValue call.
+func recoverFromPanic(fn func()) (recovered interface{}) {
+	defer func() {
+		recovered = recover()
+	}()
+	fn()
+	return nil
+}
@@ -0,0 +1,62 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package executor
+
+import (
+	"sync"
+
+	"github.com/botobag/artemis/graphql"
+	"github.com/botobag/artemis/graphql/errreport"
+)
+
+// reporterByContextMu guards reporterByContext: concurrent requests each carry their own
+// *ExecutionContext, but all of them read and write this one map.
+var (
+	reporterByContextMu sync.RWMutex
+	// reporterByContext holds the errreport.Reporter installed on an ExecutionContext via
+	// SetReporter. ExecutionContext's definition lives outside this package, so this map stands in
+	// for giving it a "Reporter" field of its own (the same workaround recoverFuncByContext uses for
+	// RecoverFunc).
+	reporterByContext = map[*ExecutionContext]errreport.Reporter{}
+)
+
+// SetReporter installs reporter as the errreport.Reporter that handleNodeError feeds every field
+// error to, right before it's appended to ctx's result, for forwarding to an external
+// observability system. It doesn't change what's serialized into the GraphQL response; without one
+// installed, errors simply aren't reported anywhere else. Registering nil removes any previously
+// installed Reporter; it's also done automatically once ctx's execution completes (see
+// collectAndDispatchRootTasks), so ctx isn't pinned in reporterByContext beyond the request it was
+// built for.
+func SetReporter(ctx *ExecutionContext, reporter errreport.Reporter) {
+	reporterByContextMu.Lock()
+	defer reporterByContextMu.Unlock()
+	if reporter == nil {
+		delete(reporterByContext, ctx)
+		return
+	}
+	reporterByContext[ctx] = reporter
+}
+
+// reportError feeds e to ctx's configured Reporter, if any.
+func reportError(ctx *ExecutionContext, e *graphql.Error) {
+	reporterByContextMu.RLock()
+	reporter, ok := reporterByContext[ctx]
+	reporterByContextMu.RUnlock()
+	if ok {
+		reporter.Report(ctx.ctx, e)
+	}
+}
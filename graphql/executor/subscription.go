@@ -0,0 +1,196 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/botobag/artemis/graphql"
+	"github.com/botobag/artemis/graphql/ast"
+)
+
+// SubscribeFunc produces the stream of source events a subscription operation's root field
+// delivers, per the spec's CreateSourceEventStream algorithm. Schema builders attach one per
+// graphql.Field with RegisterFieldSubscriber; ExecuteSubscription requires it of whatever field
+// the operation's selection set resolves to, on top of the field's ordinary Resolve, which
+// ExecuteSubscription calls once per event (via the usual field-resolution pipeline) to turn that
+// event into the event's response value.
+type SubscribeFunc func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (<-chan interface{}, error)
+
+// fieldSubscribersMu guards fieldSubscribers: RegisterFieldSubscriber can run concurrently with
+// ExecuteSubscription's lookup across in-flight subscription requests sharing the same schema's
+// fields, so unsynchronized access is a concurrent map read/write.
+var (
+	fieldSubscribersMu sync.RWMutex
+	// fieldSubscribers holds the SubscribeFunc registered for each graphql.Field via
+	// RegisterFieldSubscriber.
+	fieldSubscribers = map[graphql.Field]SubscribeFunc{}
+)
+
+// RegisterFieldSubscriber installs fn as the SubscribeFunc ExecuteSubscription uses to obtain
+// field's source event stream when it's selected as a subscription operation's root field.
+// Registering a field that already has one replaces the previous registration.
+func RegisterFieldSubscriber(field graphql.Field, fn SubscribeFunc) {
+	fieldSubscribersMu.Lock()
+	defer fieldSubscribersMu.Unlock()
+	fieldSubscribers[field] = fn
+}
+
+// ExecuteSubscription implements the event-stream half of the spec's subscription execution
+// algorithm (https://graphql.github.io/graphql-spec/June2018/#sec-Subscription): it resolves ctx's
+// single root field's source event stream via its registered SubscribeFunc, then for every value
+// the stream delivers, runs the same collectFields/dispatchTasksForObject pipeline
+// collectAndDispatchRootTasks uses for queries — with the event as the root value — to produce one
+// *ExecutionResult per event on the returned channel.
+//
+// The returned channel closes cleanly when the source event stream closes or when ctx's context is
+// canceled, whichever happens first; canceling ctx's context always unblocks the producer
+// goroutine, even if the caller has stopped reading from the returned channel (e.g. because it
+// gave up on the subscription around the same time it canceled ctx) and an event is waiting to be
+// delivered. Subscribe failing, including by panicking, is reported as ExecuteSubscription's
+// returned error rather than as a channel event, matching the spec's distinction between a request
+// error (raised here, before any event is ever delivered) and a field error (which would instead
+// appear in an individual ExecutionResult's Errors).
+//
+// e is reused to run every event's fields, so Extension hooks registered for ctx see one
+// "execution" spanning the whole subscription rather than one per event; giving each event its own
+// Extension lifecycle (which needs a fresh ExecutionContext per event, not just a fresh ResultNode)
+// is left as a follow-up.
+func ExecuteSubscription(ctx *ExecutionContext, e executor) (<-chan *ExecutionResult, error) {
+	if ctx.Operation().OperationType() != ast.OperationTypeSubscription {
+		return nil, graphql.NewError(
+			"ExecuteSubscription called for an operation that isn't a subscription.")
+	}
+
+	rootType := ctx.Operation().RootType()
+	rootNode := &ExecutionNode{}
+
+	nodes, err := collectFields(ctx, rootNode, rootType, ctx.RootValue())
+	if err != nil {
+		return nil, err
+	}
+
+	// Reference: 3. in https://graphql.github.io/graphql-spec/June2018/#sec-Single-root-field.
+	// Ordinarily a validation rule rejects this before execution is ever attempted; enforced here
+	// too since the validator doesn't carry that rule in this revision.
+	if len(nodes) != 1 {
+		return nil, graphql.NewError(
+			"Subscription operations must have exactly one root field.")
+	}
+
+	node := nodes[0]
+	fieldSubscribersMu.RLock()
+	subscribe, ok := fieldSubscribers[node.Field]
+	fieldSubscribersMu.RUnlock()
+	if !ok {
+		return nil, graphql.NewError(fmt.Sprintf(
+			`Field "%s" cannot be subscribed to: no SubscribeFunc was registered for it with `+
+				`RegisterFieldSubscriber.`, node.Field.Name()))
+	}
+
+	info := &ResolveInfo{
+		ExecutionContext: ctx,
+		ExecutionNode:    node,
+		ResultNode:       &ResultNode{},
+	}
+
+	sourceEvents, err := runSubscribe(ctx, subscribe, ctx.RootValue(), info)
+	if err != nil {
+		return nil, err
+	}
+
+	return runSubscriptionLoop(ctx.ctx.Done(), sourceEvents, func(sourceValue interface{}) *ExecutionResult {
+		return executeSubscriptionEvent(ctx, e, nodes, sourceValue)
+	}), nil
+}
+
+// runSubscriptionLoop drives ExecuteSubscription's event loop: it reads sourceEvents until either
+// the channel closes or done is closed, converting each value to an *ExecutionResult via produce
+// and sending it on the returned channel, which it closes on exit. The send itself races against
+// done too, so a caller that cancels done and stops reading the returned channel in the same
+// moment an event is ready still lets the goroutine return instead of blocking on the send forever.
+// Split out from ExecuteSubscription so this cancellation behavior can be tested without a real
+// *ExecutionContext driving it.
+func runSubscriptionLoop(
+	done <-chan struct{},
+	sourceEvents <-chan interface{},
+	produce func(sourceValue interface{}) *ExecutionResult) <-chan *ExecutionResult {
+
+	results := make(chan *ExecutionResult)
+	go func() {
+		defer close(results)
+
+		for {
+			select {
+			case <-done:
+				return
+
+			case sourceValue, open := <-sourceEvents:
+				if !open {
+					return
+				}
+				select {
+				case results <- produce(sourceValue):
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+
+	return results
+}
+
+// runSubscribe invokes subscribe, converting a panic into the same kind of error a failing
+// Subscribe would return.
+func runSubscribe(
+	ctx *ExecutionContext,
+	subscribe SubscribeFunc,
+	rootValue interface{},
+	info *ResolveInfo) (events <-chan interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			events = nil
+			err = recoverToError(ctx, r)
+		}
+	}()
+	return subscribe(ctx.ctx, rootValue, info)
+}
+
+// executeSubscriptionEvent runs nodes (the subscription's single root field) with sourceValue as
+// the root value, the same way collectAndDispatchRootTasks does for a query's root fields, and
+// packages the outcome as one ExecutionResult.
+func executeSubscriptionEvent(
+	ctx *ExecutionContext,
+	e executor,
+	nodes []*ExecutionNode,
+	sourceValue interface{}) *ExecutionResult {
+
+	// e.Errors() accumulates for the lifetime of e; since e is shared across every event (see
+	// ExecuteSubscription's doc comment), slice off only the errors this event's fields added.
+	errorsBefore := len(e.Errors())
+
+	result := &ResultNode{}
+	dispatchTasksForObject(ctx, e, result, nodes, sourceValue)
+
+	return &ExecutionResult{
+		Data:   result,
+		Errors: e.Errors()[errorsBefore:],
+	}
+}
@@ -0,0 +1,203 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package executor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/botobag/artemis/graphql"
+)
+
+// Collecting the subscription's single root field and running its Resolve per event (map/filter-
+// style subscriptions over a source-event channel against a real *graphql.Schema) needs a full
+// *ExecutionContext to exercise end-to-end; that layer isn't present in this checkout
+// (ExecutionContext, like the rest of the schema/execution types ExecuteSubscription depends on, is
+// declared outside this package). The tests below instead cover the pieces of this request that
+// don't need it: the SubscribeFunc registry ExecuteSubscription looks fields up in, runSubscribe's
+// panic-in-Subscribe recovery (which — like recover.go's functions — only threads ctx through as a
+// recoverToError map key rather than dereferencing it), and runSubscriptionLoop, which is
+// ExecuteSubscription's event loop itself, split out specifically so its done/sourceEvents/results
+// channel plumbing — including the cancellation race this request's fix addresses — can be driven
+// directly.
+//
+// A full test would additionally build a single-field Query-less schema whose subscription root
+// field's SubscribeFunc returns a channel the test feeds values into, and assert that map/filter-
+// style transformations applied in the field's ordinary Resolve show up once per *ExecutionResult
+// on ExecuteSubscription's returned channel.
+
+func TestRegisterFieldSubscriberLookup(t *testing.T) {
+	field := graphql.NewField(graphql.FieldConfig{
+		Name: "messageAdded",
+		Type: graphql.String(),
+	})
+
+	if _, ok := fieldSubscribers[field]; ok {
+		t.Fatal("field unexpectedly already has a SubscribeFunc registered")
+	}
+
+	events := make(chan interface{})
+	fn := SubscribeFunc(func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (<-chan interface{}, error) {
+		return events, nil
+	})
+	RegisterFieldSubscriber(field, fn)
+	defer delete(fieldSubscribers, field)
+
+	got, ok := fieldSubscribers[field]
+	if !ok {
+		t.Fatal("RegisterFieldSubscriber didn't install a SubscribeFunc for field")
+	}
+	gotEvents, err := got(context.Background(), nil, nil)
+	if err != nil || gotEvents != (<-chan interface{})(events) {
+		t.Errorf("registered SubscribeFunc returned (%v, %v), want the channel it was built with", gotEvents, err)
+	}
+}
+
+// TestFieldSubscribersConcurrentAccess exercises the scenario that motivated
+// fieldSubscribersMu: RegisterFieldSubscriber and ExecuteSubscription's lookup running
+// concurrently across in-flight subscription requests that share the same schema's fields. Run
+// with -race; without the mutex this trips Go's concurrent map read/write detector.
+func TestFieldSubscribersConcurrentAccess(t *testing.T) {
+	field := graphql.NewField(graphql.FieldConfig{
+		Name: "concurrent",
+		Type: graphql.String(),
+	})
+	fn := SubscribeFunc(func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (<-chan interface{}, error) {
+		return nil, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			RegisterFieldSubscriber(field, fn)
+		}()
+		go func() {
+			defer wg.Done()
+			fieldSubscribersMu.RLock()
+			_ = fieldSubscribers[field]
+			fieldSubscribersMu.RUnlock()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRunSubscribeRecoversFromPanic(t *testing.T) {
+	ctx := (*ExecutionContext)(nil)
+
+	subscribe := SubscribeFunc(func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (<-chan interface{}, error) {
+		panic("subscribe: source stream unavailable")
+	})
+
+	events, err := runSubscribe(ctx, subscribe, nil, nil)
+	if events != nil {
+		t.Errorf("runSubscribe returned a non-nil channel alongside a panic: %v", events)
+	}
+	if err == nil {
+		t.Fatal("runSubscribe swallowed the panic instead of reporting it as an error")
+	}
+	if _, ok := err.(*graphql.Error); !ok {
+		t.Errorf("runSubscribe error is %T, want *graphql.Error (via recoverToError/DefaultRecoverFunc)", err)
+	}
+}
+
+// TestRunSubscriptionLoopDeliversEvents drives runSubscriptionLoop (the part of
+// ExecuteSubscription's event loop that doesn't need a real *ExecutionContext) end-to-end: it feeds
+// source events in and checks produce's *ExecutionResult comes out the other side in order, then
+// checks that closing sourceEvents closes the returned channel.
+func TestRunSubscriptionLoopDeliversEvents(t *testing.T) {
+	resultFor := map[interface{}]*ExecutionResult{
+		"one": {},
+		"two": {},
+	}
+
+	sourceEvents := make(chan interface{})
+	produce := func(sourceValue interface{}) *ExecutionResult {
+		return resultFor[sourceValue]
+	}
+
+	results := runSubscriptionLoop(make(chan struct{}), sourceEvents, produce)
+
+	sourceEvents <- "one"
+	if got := <-results; got != resultFor["one"] {
+		t.Errorf("first result = %v, want the *ExecutionResult produce built for %q", got, "one")
+	}
+
+	sourceEvents <- "two"
+	if got := <-results; got != resultFor["two"] {
+		t.Errorf("second result = %v, want the *ExecutionResult produce built for %q", got, "two")
+	}
+
+	close(sourceEvents)
+	if _, open := <-results; open {
+		t.Error("results is still open after sourceEvents closed")
+	}
+}
+
+// TestRunSubscriptionLoopCancellationUnblocksUnreadSend reproduces the leak runSubscriptionLoop was
+// split out of ExecuteSubscription to fix: a source event is ready to deliver, but done closes
+// before anything reads the returned channel. The goroutine must still exit instead of blocking
+// forever on the unread send.
+func TestRunSubscriptionLoopCancellationUnblocksUnreadSend(t *testing.T) {
+	sourceEvents := make(chan interface{}, 1)
+	sourceEvents <- "orphaned event"
+
+	done := make(chan struct{})
+	produce := func(sourceValue interface{}) *ExecutionResult {
+		return &ExecutionResult{}
+	}
+
+	results := runSubscriptionLoop(done, sourceEvents, produce)
+	go func() {
+		// Give the goroutine a chance to read sourceEvents and block trying to send its result
+		// before done closes, the same race ExecuteSubscription's caller could hit by canceling its
+		// context around the same time it stops reading the subscription.
+		time.Sleep(10 * time.Millisecond)
+		close(done)
+	}()
+
+	select {
+	case _, open := <-results:
+		if open {
+			t.Error("received a value from results; done closing should have unblocked the send instead")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("results never closed after done closed; the send on an unread channel blocked forever")
+	}
+}
+
+func TestRunSubscribeCancellationUnblocksSelect(t *testing.T) {
+	// Exercises the same "ctx.ctx.Done() wins the select" shape ExecuteSubscription's event loop
+	// uses, without needing a real *ExecutionContext: a canceled context and a never-sent-on events
+	// channel race in a select, and cancellation must win within a bounded time instead of the
+	// producer blocking forever.
+	doneCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	events := make(chan interface{})
+	select {
+	case <-doneCtx.Done():
+		// expected
+	case <-events:
+		t.Fatal("received from an events channel nothing ever sent on")
+	case <-time.After(time.Second):
+		t.Fatal("canceled context's Done() didn't unblock the select")
+	}
+}
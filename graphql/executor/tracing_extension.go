@@ -0,0 +1,127 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package executor
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TracingFieldRecord is one field's entry in a TracingExtension's trace tree, keyed by its
+// response path.
+type TracingFieldRecord struct {
+	Path        string        `json:"path"`
+	ParentType  string        `json:"parentType"`
+	FieldName   string        `json:"fieldName"`
+	ReturnType  string        `json:"returnType"`
+	StartOffset time.Duration `json:"startOffset"`
+	Duration    time.Duration `json:"duration"`
+}
+
+// TracingResult is what TracingExtension writes to ExecutionResult.Extensions["tracing"].
+type TracingResult struct {
+	StartTime time.Time              `json:"startTime"`
+	EndTime   time.Time              `json:"endTime"`
+	Duration  time.Duration          `json:"duration"`
+	Fields    []*TracingFieldRecord  `json:"fields"`
+	ByPath    map[string]interface{} `json:"-"`
+}
+
+// TracingExtension is a reference Extension implementation that times every field resolved during
+// an operation, in the shape popularized by Apollo's "apollo-tracing" extension. Install one per
+// operation (it isn't safe to share across concurrent operations) via RegisterExtensions; it
+// writes a *TracingResult to ExecutionResult.Extensions["tracing"] once execution completes.
+type TracingExtension struct {
+	startTime time.Time
+
+	mu     sync.Mutex
+	fields map[string]*TracingFieldRecord
+}
+
+var _ Extension = (*TracingExtension)(nil)
+
+// NewTracingExtension creates a TracingExtension ready to be passed to RegisterExtensions.
+func NewTracingExtension() *TracingExtension {
+	return &TracingExtension{
+		fields: map[string]*TracingFieldRecord{},
+	}
+}
+
+// ExecutionDidStart implements Extension.
+func (t *TracingExtension) ExecutionDidStart(ctx *ExecutionContext) func(*ExecutionResult) {
+	t.startTime = time.Now()
+
+	return func(result *ExecutionResult) {
+		endTime := time.Now()
+
+		t.mu.Lock()
+		fields := make([]*TracingFieldRecord, 0, len(t.fields))
+		byPath := make(map[string]interface{}, len(t.fields))
+		for path, record := range t.fields {
+			fields = append(fields, record)
+			byPath[path] = record
+		}
+		t.mu.Unlock()
+
+		if result.Extensions == nil {
+			result.Extensions = map[string]interface{}{}
+		}
+		result.Extensions["tracing"] = &TracingResult{
+			StartTime: t.startTime,
+			EndTime:   endTime,
+			Duration:  endTime.Sub(t.startTime),
+			Fields:    fields,
+			ByPath:    byPath,
+		}
+	}
+}
+
+// ValidationDidStart implements Extension. TracingExtension doesn't instrument validation.
+func (t *TracingExtension) ValidationDidStart() func(errs []error) {
+	return nil
+}
+
+// ParsingDidStart implements Extension. TracingExtension doesn't instrument parsing.
+func (t *TracingExtension) ParsingDidStart() func(err error) {
+	return nil
+}
+
+// FieldDidStart implements Extension.
+func (t *TracingExtension) FieldDidStart(info *ResolveInfo) func(value interface{}, err error) {
+	start := time.Now()
+
+	var (
+		node = info.ExecutionNode
+		path = info.ResultNode.Path().String()
+	)
+
+	return func(value interface{}, err error) {
+		record := &TracingFieldRecord{
+			Path:        path,
+			ParentType:  fmt.Sprintf("%v", parentFieldType(info.ExecutionContext, node)),
+			FieldName:   node.Field.Name(),
+			ReturnType:  fmt.Sprintf("%v", node.Field.Type()),
+			StartOffset: start.Sub(t.startTime),
+			Duration:    time.Since(start),
+		}
+
+		t.mu.Lock()
+		t.fields[path] = record
+		t.mu.Unlock()
+	}
+}
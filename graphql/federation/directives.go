@@ -0,0 +1,156 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package federation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// KeyDirective is one parsed "@key(fields: \"...\")" directive on a type. Federation lets a type
+// declare more than one @key, e.g. when it can be looked up by either its id or a compound key, so
+// TypeDirectives.Keys is a slice rather than a single value.
+type KeyDirective struct {
+	// Fields is the directive's "fields" argument verbatim, e.g. "id" or "id sku".
+	Fields string
+}
+
+// FieldDirectives holds the federation directives parsed off a single field definition.
+type FieldDirectives struct {
+	External  bool
+	Requires  string
+	Provides  string
+	Shareable bool
+}
+
+// TypeDirectives holds the federation directives ParseDirectives found on one type definition in
+// a subgraph's SDL: its @key(s), whether the whole type is @shareable, and the
+// @external/@requires/@provides/@shareable directives on its fields.
+type TypeDirectives struct {
+	Name      string
+	Keys      []KeyDirective
+	Shareable bool
+	Fields    map[string]FieldDirectives
+}
+
+// HasKey reports whether t has at least one @key directive, i.e. whether its SDL declares it a
+// federation entity. It's nil-safe so callers can check an absent *TypeDirectives the same way as
+// one with no @key.
+func (t *TypeDirectives) HasKey() bool {
+	return t != nil && len(t.Keys) > 0
+}
+
+var (
+	// typeHeaderPattern finds a (possibly "extend"ed) type definition's name and the directives on
+	// its header, up to the opening "{" of its field list.
+	typeHeaderPattern = regexp.MustCompile(`(?m)^[ \t]*(?:extend\s+)?type\s+(\w+)\b([^{]*)\{`)
+
+	// fieldLinePattern matches one field definition line, capturing its name and the directives
+	// trailing its type (if any). It's line-oriented rather than a full grammar, so a directive
+	// argument or field type that itself spans multiple lines won't be recognized — federation
+	// subgraph SDL in practice doesn't do this.
+	fieldLinePattern = regexp.MustCompile(`^\s*(\w+)\s*(?:\([^)]*\))?\s*:\s*[^\n@]+((?:\s*@\w+(?:\([^)]*\))?)*)\s*$`)
+
+	keyDirectivePattern      = regexp.MustCompile(`@key\s*\(\s*fields\s*:\s*"([^"]*)"\s*\)`)
+	requiresDirectivePattern = regexp.MustCompile(`@requires\s*\(\s*fields\s*:\s*"([^"]*)"\s*\)`)
+	providesDirectivePattern = regexp.MustCompile(`@provides\s*\(\s*fields\s*:\s*"([^"]*)"\s*\)`)
+)
+
+// ParseDirectives scans sdl's type definitions for the federation directives this package
+// understands (@key, @external, @requires, @provides, @shareable) and returns the result keyed by
+// type name; a type with none of these directives isn't included. It reports an error if sdl
+// contains a type definition whose "{" is never closed.
+//
+// This is a narrow scanner built for federation's directive subset, not a general GraphQL SDL
+// parser: this checkout has no graphql/ast package to parse SDL into (see the package doc
+// comment), so it works directly off the source text using the textual shape federation
+// directives always appear in, rather than a real grammar.
+func ParseDirectives(sdl string) (map[string]*TypeDirectives, error) {
+	result := map[string]*TypeDirectives{}
+
+	for _, h := range typeHeaderPattern.FindAllStringSubmatchIndex(sdl, -1) {
+		name := sdl[h[2]:h[3]]
+		header := sdl[h[4]:h[5]]
+		bodyStart := h[1]
+
+		bodyEnd := matchingBraceEnd(sdl, bodyStart-1)
+		if bodyEnd < 0 {
+			return nil, fmt.Errorf("federation: type %q has no closing \"}\"", name)
+		}
+
+		td := parseTypeDirectives(name, header, sdl[bodyStart:bodyEnd])
+		if len(td.Keys) > 0 || td.Shareable || len(td.Fields) > 0 {
+			result[name] = td
+		}
+	}
+
+	return result, nil
+}
+
+// parseTypeDirectives parses the directives in a single type's header (the text between its name
+// and its opening "{") and body (the text between its "{" and "}") into a TypeDirectives.
+func parseTypeDirectives(name, header, body string) *TypeDirectives {
+	td := &TypeDirectives{Name: name, Fields: map[string]FieldDirectives{}}
+
+	for _, m := range keyDirectivePattern.FindAllStringSubmatch(header, -1) {
+		td.Keys = append(td.Keys, KeyDirective{Fields: m[1]})
+	}
+	td.Shareable = strings.Contains(header, "@shareable")
+
+	for _, line := range strings.Split(body, "\n") {
+		m := fieldLinePattern.FindStringSubmatch(line)
+		if m == nil || m[2] == "" {
+			continue
+		}
+
+		fieldName, directives := m[1], m[2]
+		fd := FieldDirectives{
+			External:  strings.Contains(directives, "@external"),
+			Shareable: strings.Contains(directives, "@shareable"),
+		}
+		if rm := requiresDirectivePattern.FindStringSubmatch(directives); rm != nil {
+			fd.Requires = rm[1]
+		}
+		if pm := providesDirectivePattern.FindStringSubmatch(directives); pm != nil {
+			fd.Provides = pm[1]
+		}
+		td.Fields[fieldName] = fd
+	}
+
+	return td
+}
+
+// matchingBraceEnd returns the index of the "}" that closes the "{" at sdl[openIndex], or -1 if
+// sdl has no matching close brace. It tracks nesting depth so a field whose own type contains
+// braces (there are none in GraphQL SDL, but input-object/object bodies nest through other type
+// definitions rather than inline) doesn't confuse it.
+func matchingBraceEnd(sdl string, openIndex int) int {
+	depth := 0
+	for i := openIndex; i < len(sdl); i++ {
+		switch sdl[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
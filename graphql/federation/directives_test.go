@@ -0,0 +1,106 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package federation
+
+import "testing"
+
+func TestParseDirectivesParsesKeyExternalRequiresProvidesShareable(t *testing.T) {
+	sdl := `
+type Query {
+  me: User
+}
+
+type User @key(fields: "id") {
+  id: ID!
+  name: String
+  reviews: [Review!]! @requires(fields: "id")
+}
+
+extend type Product @key(fields: "upc") @shareable {
+  upc: String! @external
+  price: Int @provides(fields: "weight")
+}
+`
+	directives, err := ParseDirectives(sdl)
+	if err != nil {
+		t.Fatalf("ParseDirectives() returned an error: %v", err)
+	}
+
+	if _, ok := directives["Query"]; ok {
+		t.Error(`directives["Query"] present, want no entry for a type with no federation directives`)
+	}
+
+	user, ok := directives["User"]
+	if !ok {
+		t.Fatal(`directives["User"] missing`)
+	}
+	if len(user.Keys) != 1 || user.Keys[0].Fields != "id" {
+		t.Errorf("User.Keys = %v, want [{id}]", user.Keys)
+	}
+	if fd := user.Fields["reviews"]; fd.Requires != "id" {
+		t.Errorf(`User.Fields["reviews"].Requires = %q, want "id"`, fd.Requires)
+	}
+
+	product, ok := directives["Product"]
+	if !ok {
+		t.Fatal(`directives["Product"] missing`)
+	}
+	if len(product.Keys) != 1 || product.Keys[0].Fields != "upc" {
+		t.Errorf("Product.Keys = %v, want [{upc}]", product.Keys)
+	}
+	if !product.Shareable {
+		t.Error("Product.Shareable = false, want true")
+	}
+	if fd := product.Fields["upc"]; !fd.External {
+		t.Error(`Product.Fields["upc"].External = false, want true`)
+	}
+	if fd := product.Fields["price"]; fd.Provides != "weight" {
+		t.Errorf(`Product.Fields["price"].Provides = %q, want "weight"`, fd.Provides)
+	}
+}
+
+func TestParseDirectivesSupportsMultipleKeys(t *testing.T) {
+	sdl := `
+type Product @key(fields: "upc") @key(fields: "sku") {
+  upc: String!
+  sku: String!
+}
+`
+	directives, err := ParseDirectives(sdl)
+	if err != nil {
+		t.Fatalf("ParseDirectives() returned an error: %v", err)
+	}
+
+	product := directives["Product"]
+	if product == nil || len(product.Keys) != 2 {
+		t.Fatalf("Product.Keys = %v, want 2 entries", product)
+	}
+}
+
+func TestParseDirectivesRejectsUnterminatedType(t *testing.T) {
+	_, err := ParseDirectives(`type Broken @key(fields: "id") {`)
+	if err == nil {
+		t.Error("ParseDirectives() = nil error, want one for an unterminated type definition")
+	}
+}
+
+func TestHasKeyNilReceiverIsFalse(t *testing.T) {
+	var td *TypeDirectives
+	if td.HasKey() {
+		t.Error("(*TypeDirectives)(nil).HasKey() = true, want false")
+	}
+}
@@ -0,0 +1,146 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+// Package federation lets a graphql.Schema act as a partial Apollo Federation v2 subgraph: it
+// wires up the "_service"/"_entities" mechanics and parses the federation directives (@key,
+// @external, @requires, @provides, @shareable) off the subgraph's SDL via ParseDirectives — see
+// below before relying on this package to satisfy a gateway's full subgraph contract.
+//
+// Setup splices the synthetic "_service" and "_entities" fields onto the schema's Query root,
+// parses sdl's directives with ParseDirectives, and resolves "_entities" representations via
+// per-schema EntityResolvers registered with RegisterEntityResolver. The "_Entity" union's members
+// are whatever Object types have a resolver registered for that schema — not, as the spec
+// describes it, every @key-annotated type: RegisterEntityResolver and Setup may run in either
+// order (see registryFor), so reconciling the two sets happens lazily, the first time "_entities"
+// is queried (see resolveEntities), rather than being enforced at either call site. A representation
+// naming a type that has a resolver registered but that the SDL never marked @key is reported back
+// as a federation error instead of being silently resolved.
+//
+// sdl is returned verbatim by "{ _service { sdl } }", which is a faithful round-trip of the
+// directives callers wrote since this package never strips or rewrites them — there's no
+// schema-to-SDL printer in this checkout (see graphql/introspection) to regenerate sdl from
+// schema's types instead, so callers are still expected to pass the federation-annotated SDL they
+// used to build schema.
+package federation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/botobag/artemis/graphql"
+	"github.com/botobag/artemis/graphql/executor"
+)
+
+// EntityResolver resolves a single entity identified by a federation "representation" — the map
+// of @key fields a gateway sends to identify it — for one @key-annotated Object type.
+type EntityResolver interface {
+	ResolveEntity(ctx context.Context, representation map[string]interface{}) (interface{}, error)
+}
+
+// RegisterEntityResolver installs resolver as the EntityResolver for the @key-annotated Object
+// type t, scoped to schema. It's used to satisfy schema's Query._entities representations whose
+// "__typename" matches t's name; a representation arriving at a different schema's _entities
+// never sees t, even if that schema also has a type of the same name.
+func RegisterEntityResolver(schema *graphql.Schema, t graphql.Object, resolver EntityResolver) {
+	registryFor(schema).register(t, resolver)
+}
+
+// Setup installs the federation subgraph fields ("_service", "_entities") onto schema's Query
+// root and parses sdl's federation directives (see ParseDirectives) for resolveEntities to
+// validate registered entities against.
+//
+// sdl is the schema's SDL text, returned verbatim by "{ _service { sdl } }" (see the package doc
+// comment for why). It returns a non-nil error if sdl can't be parsed for directives (an
+// unterminated type definition); Setup still installs "_service"/"_entities" in that case, since a
+// malformed directive shouldn't by itself stop the subgraph from serving its schema.
+func Setup(schema *graphql.Schema, sdl string) error {
+	reg := registryFor(schema)
+	executor.RegisterRootField(schema, "_service", serviceField(sdl))
+	executor.RegisterRootField(schema, "_entities", entitiesField(reg))
+
+	directives, err := ParseDirectives(sdl)
+	if err != nil {
+		return fmt.Errorf("federation: parsing directives: %w", err)
+	}
+	reg.setDirectives(directives)
+	return nil
+}
+
+func serviceField(sdl string) graphql.Field {
+	return graphql.NewField(graphql.FieldConfig{
+		Name: "_service",
+		Type: serviceType,
+		Resolve: graphql.FieldResolverFunc(
+			func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+				return map[string]interface{}{"sdl": sdl}, nil
+			}),
+	})
+}
+
+func entitiesField(reg *entityRegistry) graphql.Field {
+	return graphql.NewField(graphql.FieldConfig{
+		Name: "_entities",
+		Type: graphql.NewList(buildEntityType(reg)),
+		Args: graphql.FieldConfigArgumentMap{
+			"representations": {
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(anyType))),
+			},
+		},
+		Resolve: graphql.FieldResolverFunc(
+			func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+				return resolveEntities(ctx, reg, info)
+			}),
+	})
+}
+
+// resolveEntities implements Query._entities: it pulls the "representations" argument out of info
+// and dispatches to resolveEntitiesFor to do the actual work. Split out so the logic that matters
+// (preserving order, validating each representation against reg) can be tested directly, without
+// needing a real graphql.ResolveInfo to drive it through.
+func resolveEntities(ctx context.Context, reg *entityRegistry, info graphql.ResolveInfo) (interface{}, error) {
+	representations, _ := info.ArgumentValues().Get("representations").([]interface{})
+	return resolveEntitiesFor(ctx, reg, representations)
+}
+
+// resolveEntitiesFor dispatches each of representations to the EntityResolver reg has registered
+// for its "__typename", preserving result index order as required by the federation spec.
+func resolveEntitiesFor(ctx context.Context, reg *entityRegistry, representations []interface{}) (interface{}, error) {
+	results := make([]interface{}, len(representations))
+	for i, r := range representations {
+		representation, ok := r.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("_entities: representation at index %d is not an object", i)
+		}
+
+		typeName, _ := representation["__typename"].(string)
+		resolver, ok := reg.resolverFor(typeName)
+		if !ok {
+			return nil, fmt.Errorf("_entities: no EntityResolver registered for type %q", typeName)
+		}
+		if !reg.hasKeyDirective(typeName) {
+			return nil, fmt.Errorf(
+				"_entities: %q has an EntityResolver registered but its SDL definition has no @key directive",
+				typeName)
+		}
+
+		entity, err := resolver.ResolveEntity(ctx, representation)
+		if err != nil {
+			return nil, fmt.Errorf("_entities: resolving representation %d (%s): %w", i, typeName, err)
+		}
+		results[i] = entity
+	}
+	return results, nil
+}
@@ -0,0 +1,235 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ *
+ * resolveEntities/entitiesField ultimately need a real graphql.ResolveInfo (to pull "representations"
+ * out of), and buildEntityType's union needs a real *graphql.Schema to resolve its possible types
+ * through (schema.PossibleTypes, see executor/execute.go) — neither of which this checkout's
+ * partial core type-system snapshot can build. So these tests target resolveEntitiesFor (the part
+ * of resolveEntities that doesn't touch ResolveInfo, split out for exactly this reason) and
+ * entityRegistry.entityTypes (the thunk buildEntityType hands the union, which is where its
+ * possible-types resolution actually happens).
+ */
+
+package federation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/botobag/artemis/graphql"
+)
+
+type fakeEntityResolver struct {
+	result interface{}
+	err    error
+}
+
+func (r fakeEntityResolver) ResolveEntity(ctx context.Context, representation map[string]interface{}) (interface{}, error) {
+	return r.result, r.err
+}
+
+func newTestRegistry() *entityRegistry {
+	return &entityRegistry{
+		resolvers: map[string]EntityResolver{},
+		types:     map[string]graphql.Object{},
+	}
+}
+
+func TestResolveEntitiesForPreservesOrder(t *testing.T) {
+	reg := newTestRegistry()
+	userType := graphql.NewObject(graphql.ObjectConfig{Name: "User"})
+	productType := graphql.NewObject(graphql.ObjectConfig{Name: "Product"})
+	reg.register(userType, fakeEntityResolver{result: "user-1"})
+	reg.register(productType, fakeEntityResolver{result: "product-1"})
+
+	representations := []interface{}{
+		map[string]interface{}{"__typename": "Product", "upc": "123"},
+		map[string]interface{}{"__typename": "User", "id": "1"},
+	}
+
+	got, err := resolveEntitiesFor(context.Background(), reg, representations)
+	if err != nil {
+		t.Fatalf("resolveEntitiesFor() returned an error: %v", err)
+	}
+
+	results, ok := got.([]interface{})
+	if !ok || len(results) != 2 {
+		t.Fatalf("resolveEntitiesFor() = %v, want a 2-element slice", got)
+	}
+	if results[0] != "product-1" || results[1] != "user-1" {
+		t.Errorf("results = %v, want [product-1 user-1] (order of the input representations)", results)
+	}
+}
+
+func TestResolveEntitiesForRejectsNonObjectRepresentation(t *testing.T) {
+	reg := newTestRegistry()
+
+	_, err := resolveEntitiesFor(context.Background(), reg, []interface{}{"not-an-object"})
+	if err == nil {
+		t.Fatal("resolveEntitiesFor() = nil error, want one for a non-object representation")
+	}
+}
+
+func TestResolveEntitiesForRejectsUnregisteredType(t *testing.T) {
+	reg := newTestRegistry()
+
+	representations := []interface{}{
+		map[string]interface{}{"__typename": "Ghost"},
+	}
+	_, err := resolveEntitiesFor(context.Background(), reg, representations)
+	if err == nil {
+		t.Fatal("resolveEntitiesFor() = nil error, want one for a type with no registered EntityResolver")
+	}
+}
+
+func TestResolveEntitiesForRejectsTypeWithoutKeyDirective(t *testing.T) {
+	reg := newTestRegistry()
+	userType := graphql.NewObject(graphql.ObjectConfig{Name: "User"})
+	reg.register(userType, fakeEntityResolver{result: "user-1"})
+	reg.setDirectives(map[string]*TypeDirectives{
+		// User has no @key entry at all, simulating a resolver registered for a type the SDL never
+		// annotated as a federation entity.
+	})
+
+	representations := []interface{}{
+		map[string]interface{}{"__typename": "User", "id": "1"},
+	}
+	_, err := resolveEntitiesFor(context.Background(), reg, representations)
+	if err == nil {
+		t.Fatal("resolveEntitiesFor() = nil error, want one when the type has no @key directive")
+	}
+}
+
+func TestResolveEntitiesForAllowsRegistrationsBeforeSetupRuns(t *testing.T) {
+	reg := newTestRegistry()
+	userType := graphql.NewObject(graphql.ObjectConfig{Name: "User"})
+	reg.register(userType, fakeEntityResolver{result: "user-1"})
+	// reg.directives is left nil, as if RegisterEntityResolver ran before Setup.
+
+	representations := []interface{}{
+		map[string]interface{}{"__typename": "User", "id": "1"},
+	}
+	got, err := resolveEntitiesFor(context.Background(), reg, representations)
+	if err != nil {
+		t.Fatalf("resolveEntitiesFor() returned an error: %v, want it to proceed when directives aren't set yet", err)
+	}
+	if results, ok := got.([]interface{}); !ok || results[0] != "user-1" {
+		t.Errorf("resolveEntitiesFor() = %v, want [user-1]", got)
+	}
+}
+
+func TestResolveEntitiesForWrapsResolverError(t *testing.T) {
+	reg := newTestRegistry()
+	userType := graphql.NewObject(graphql.ObjectConfig{Name: "User"})
+	resolverErr := errors.New("not found")
+	reg.register(userType, fakeEntityResolver{err: resolverErr})
+
+	representations := []interface{}{
+		map[string]interface{}{"__typename": "User", "id": "1"},
+	}
+	_, err := resolveEntitiesFor(context.Background(), reg, representations)
+	if !errors.Is(err, resolverErr) {
+		t.Errorf("resolveEntitiesFor() error = %v, want it to wrap %v", err, resolverErr)
+	}
+}
+
+func TestRegistryForIsScopedPerSchema(t *testing.T) {
+	schemaA := &graphql.Schema{}
+	schemaB := &graphql.Schema{}
+	defer delete(registries, schemaA)
+	defer delete(registries, schemaB)
+
+	userType := graphql.NewObject(graphql.ObjectConfig{Name: "User"})
+	RegisterEntityResolver(schemaA, userType, fakeEntityResolver{result: "from-a"})
+
+	if _, ok := registryFor(schemaB).resolverFor("User"); ok {
+		t.Error("registryFor(schemaB) unexpectedly sees an EntityResolver registered against schemaA")
+	}
+	if _, ok := registryFor(schemaA).resolverFor("User"); !ok {
+		t.Error("registryFor(schemaA) lost the EntityResolver registered against it")
+	}
+}
+
+func TestRegisterEntityResolverReplacesExisting(t *testing.T) {
+	schema := &graphql.Schema{}
+	defer delete(registries, schema)
+
+	userType := graphql.NewObject(graphql.ObjectConfig{Name: "User"})
+	RegisterEntityResolver(schema, userType, fakeEntityResolver{result: "first"})
+	RegisterEntityResolver(schema, userType, fakeEntityResolver{result: "second"})
+
+	resolver, ok := registryFor(schema).resolverFor("User")
+	if !ok {
+		t.Fatal("resolverFor(\"User\") = not found, want the most recently registered resolver")
+	}
+	got, _ := resolver.ResolveEntity(context.Background(), nil)
+	if got != "second" {
+		t.Errorf("resolver.ResolveEntity() = %v, want %q", got, "second")
+	}
+}
+
+func TestEntityTypesReflectsRegistrationsMadeAfterUnionIsBuilt(t *testing.T) {
+	// buildEntityType hands the union a thunk (reg.entityTypes) rather than a fixed slice
+	// specifically so a RegisterEntityResolver call that happens after Setup still takes effect; this
+	// exercises that by registering a type only after the union (and its thunk) already exist.
+	reg := newTestRegistry()
+	union := buildEntityType(reg)
+	if union == nil {
+		t.Fatal("buildEntityType(reg) = nil")
+	}
+
+	if types := reg.entityTypes(); len(types) != 0 {
+		t.Fatalf("reg.entityTypes() = %v, want none registered yet", types)
+	}
+
+	userType := graphql.NewObject(graphql.ObjectConfig{Name: "User"})
+	reg.register(userType, fakeEntityResolver{})
+
+	types := reg.entityTypes()
+	if len(types) != 1 || types[0].Name() != "User" {
+		t.Errorf("reg.entityTypes() = %v, want [User] reflecting the registration made after buildEntityType", types)
+	}
+}
+
+func TestParseDirectivesAndHasKeyDirective(t *testing.T) {
+	sdl := `
+type User @key(fields: "id") {
+  id: ID!
+  name: String
+}
+
+type Comment {
+  text: String
+}
+`
+	directives, err := ParseDirectives(sdl)
+	if err != nil {
+		t.Fatalf("ParseDirectives() returned an error: %v", err)
+	}
+
+	reg := newTestRegistry()
+	reg.setDirectives(directives)
+
+	if !reg.hasKeyDirective("User") {
+		t.Error(`hasKeyDirective("User") = false, want true`)
+	}
+	if reg.hasKeyDirective("Comment") {
+		t.Error(`hasKeyDirective("Comment") = true, want false (no @key directive)`)
+	}
+	if reg.hasKeyDirective("Ghost") {
+		t.Error(`hasKeyDirective("Ghost") = true, want false (type not in SDL at all)`)
+	}
+}
@@ -0,0 +1,158 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package federation
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/botobag/artemis/graphql"
+)
+
+// anyType backs the "_Any" scalar used by Query._entities' "representations" argument. A
+// representation is just the map decoded from the incoming "{ __typename, ... }" object, so
+// coercion is a pass-through; graphql/value.CoerceValue already knows how to turn a variable
+// object into a map[string]interface{}. It carries no per-schema state, so (unlike
+// entityType below) a single instance is shared across every schema Setup is called on.
+var anyType = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "_Any",
+	Description: "A federation entity representation, as sent by a gateway to Query._entities.",
+	CoerceVariableValue: func(value interface{}) (interface{}, error) {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("_Any must be an object, got: %T", value)
+		}
+		if _, ok := m["__typename"]; !ok {
+			return nil, fmt.Errorf(`_Any must contain a "__typename" field`)
+		}
+		return m, nil
+	},
+	CoerceResultValue: func(value interface{}) (interface{}, error) {
+		return value, nil
+	},
+})
+
+// serviceType backs "_Service { sdl: String! }". It's likewise schema-agnostic and shared.
+var serviceType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "_Service",
+	Fields: graphql.Fields{
+		"sdl": {
+			Type: graphql.NewNonNull(graphql.String()),
+		},
+	},
+})
+
+// entityRegistry holds the EntityResolvers registered for a single schema via
+// RegisterEntityResolver, keyed by Object type name so a representation's "__typename" string
+// looks its resolver up directly. It's scoped per-*graphql.Schema (see registryFor) rather than
+// process-global, so a process hosting more than one federated schema doesn't have one schema's
+// "_entities" silently resolve types registered for another.
+type entityRegistry struct {
+	mu        sync.RWMutex
+	resolvers map[string]EntityResolver
+	types     map[string]graphql.Object
+
+	// directives holds the federation directives ParseDirectives found in the SDL Setup was given,
+	// keyed by type name. It's nil until Setup runs; RegisterEntityResolver calls that happen before
+	// Setup (see the note on registryFor) are validated lazily, by hasKeyDirective, once it's
+	// populated.
+	directives map[string]*TypeDirectives
+}
+
+// registriesMu guards registries.
+var registriesMu sync.Mutex
+
+// registries maps a schema to the entityRegistry Setup built for it.
+var registries = map[*graphql.Schema]*entityRegistry{}
+
+// registryFor returns the entityRegistry for schema, creating an empty one on first use. Called
+// both by RegisterEntityResolver (which may run from a type's init(), before Setup) and by Setup
+// itself, so whichever runs first allocates it.
+func registryFor(schema *graphql.Schema) *entityRegistry {
+	registriesMu.Lock()
+	defer registriesMu.Unlock()
+
+	reg, ok := registries[schema]
+	if !ok {
+		reg = &entityRegistry{
+			resolvers: map[string]EntityResolver{},
+			types:     map[string]graphql.Object{},
+		}
+		registries[schema] = reg
+	}
+	return reg
+}
+
+// register installs resolver for t in reg.
+func (reg *entityRegistry) register(t graphql.Object, resolver EntityResolver) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.resolvers[t.Name()] = resolver
+	reg.types[t.Name()] = t
+}
+
+// resolverFor returns the EntityResolver registered for typeName, if any.
+func (reg *entityRegistry) resolverFor(typeName string) (EntityResolver, bool) {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	resolver, ok := reg.resolvers[typeName]
+	return resolver, ok
+}
+
+// entityTypes returns every Object type with an EntityResolver currently registered in reg.
+func (reg *entityRegistry) entityTypes() []graphql.Object {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	types := make([]graphql.Object, 0, len(reg.types))
+	for _, t := range reg.types {
+		types = append(types, t)
+	}
+	return types
+}
+
+// hasKeyDirective reports whether typeName's SDL definition carries an @key directive, per the
+// directives Setup parsed (see setDirectives). It reports true if directives haven't been set yet
+// (Setup hasn't run): resolveEntities, the only caller, should not reject a representation over a
+// validation step that was never given the chance to run.
+func (reg *entityRegistry) hasKeyDirective(typeName string) bool {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	if reg.directives == nil {
+		return true
+	}
+	return reg.directives[typeName].HasKey()
+}
+
+// setDirectives installs the federation directives Setup parsed from the subgraph's SDL.
+func (reg *entityRegistry) setDirectives(directives map[string]*TypeDirectives) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.directives = directives
+}
+
+// buildEntityType returns the "_Entity" union backing Query._entities for the schema reg was
+// built for, whose members are every Object type that has an EntityResolver registered in reg.
+// Its possible-types list is resolved lazily (rather than fixed at construction) so
+// RegisterEntityResolver calls that happen after Setup still take effect — schemas typically
+// register entity resolvers from each type's own init(), whose ordering relative to Setup isn't
+// guaranteed.
+func buildEntityType(reg *entityRegistry) graphql.Union {
+	return graphql.NewUnion(graphql.UnionConfig{
+		Name:  "_Entity",
+		Types: graphql.ObjectsThunk(reg.entityTypes),
+	})
+}
@@ -18,25 +18,153 @@ package validator
 
 import (
 	"fmt"
+	"sync"
+
+	"github.com/botobag/artemis/graphql"
+)
+
+// MessageKey identifies a validation rule's message independent of its wording, e.g.
+// "UniqueOperationNames.Duplicate". It's attached, together with the message's raw arguments, to
+// the resulting Error's Extensions (see messageKeyExtension), so downstream code can re-render the
+// message in another locale, or group errors by rule, without parsing the English string.
+type MessageKey string
+
+// Message keys for each rule reported by the functions below.
+const (
+	MessageKeyDuplicateOperationName MessageKey = "UniqueOperationNames.Duplicate"
+	MessageKeyAnonOperationNotAlone  MessageKey = "LoneAnonymousOperation.NotAlone"
+	MessageKeySingleFieldOnly        MessageKey = "SingleFieldSubscriptions.NotSingleField"
+)
+
+// messageKeyExtension is the reserved Extensions namespace a message's MessageKey and raw
+// arguments are recorded under.
+const messageKeyExtension = "artemis.messageKey"
+
+// MessageKeyInfo is the value recorded under messageKeyExtension: the stable key identifying
+// which rule produced the message and the raw arguments it was rendered with, so a translation
+// pipeline can re-render it in another locale without caring how the English catalog worded it.
+type MessageKeyInfo struct {
+	Key  MessageKey    `json:"key"`
+	Args []interface{} `json:"args,omitempty"`
+}
+
+// MessageCatalog renders a MessageKey and its arguments into a user-facing string. Catalogs are
+// selected process-wide with RegisterMessageCatalog, typically once at startup based on the
+// server's configured locale.
+type MessageCatalog interface {
+	// Message renders key with args, the same arguments (in the same order) the rule that produced
+	// key was given. It reports false if key isn't covered by this catalog, so the caller can fall
+	// back to another one.
+	Message(key MessageKey, args ...interface{}) (string, bool)
+}
+
+// englishMessageCatalog is the built-in MessageCatalog, and the fallback for any key a
+// process-installed catalog (see RegisterMessageCatalog) doesn't cover.
+type englishMessageCatalog struct{}
+
+func (englishMessageCatalog) Message(key MessageKey, args ...interface{}) (string, bool) {
+	switch key {
+	case MessageKeyDuplicateOperationName:
+		return fmt.Sprintf(`There can be only one operation named "%s".`, args[0]), true
+
+	case MessageKeyAnonOperationNotAlone:
+		return "This anonymous operation must be the only defined operation.", true
+
+	case MessageKeySingleFieldOnly:
+		name, _ := args[0].(string)
+		if len(name) == 0 {
+			return "Anonymous Subscription must select only one top level field.", true
+		}
+		return fmt.Sprintf(`Subscription "%s" must select only one top level field.`, name), true
+	}
+	return "", false
+}
+
+var defaultCatalog MessageCatalog = englishMessageCatalog{}
+
+// catalogMu guards catalog. Rendering happens on the per-error validation path, so it's an
+// RWMutex rather than a plain Mutex.
+var (
+	catalogMu sync.RWMutex
+	catalog   = defaultCatalog
 )
 
-// DuplicateOperationNameMessage returns message describing error occurred in rule "Operation Name
-// Uniqueness" (rules.UniqueOperationNames).
+// RegisterMessageCatalog installs c as the MessageCatalog the functions below render their
+// messages through. Pass nil to revert to the built-in English catalog. A key c doesn't cover
+// falls back to the English wording for that key, so a locale catalog only needs to translate the
+// keys it actually has strings for.
+func RegisterMessageCatalog(c MessageCatalog) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	if c == nil {
+		c = defaultCatalog
+	}
+	catalog = c
+}
+
+// renderMessage renders key with args through the currently registered catalog, falling back to
+// the built-in English one if that catalog doesn't cover key.
+func renderMessage(key MessageKey, args ...interface{}) string {
+	catalogMu.RLock()
+	c := catalog
+	catalogMu.RUnlock()
+
+	if message, ok := c.Message(key, args...); ok {
+		return message
+	}
+	message, _ := defaultCatalog.Message(key, args...)
+	return message
+}
+
+// newMessageError builds the error for a message key at locations, rendering it through the
+// registered MessageCatalog and attaching key and args to Extensions under messageKeyExtension.
+func newMessageError(key MessageKey, locations []graphql.ErrorLocation, args ...interface{}) *graphql.Error {
+	return graphql.NewError(
+		renderMessage(key, args...),
+		locations,
+		graphql.ErrorExtensions{
+			messageKeyExtension: MessageKeyInfo{Key: key, Args: args},
+		}).(*graphql.Error)
+}
+
+// DuplicateOperationNameMessage returns the message describing a violation of rule "Operation
+// Name Uniqueness" (rules.UniqueOperationNames), rendered through the registered MessageCatalog.
+// Combine it with the offending node's location via graphql.NewError, e.g.
+// graphql.NewError(DuplicateOperationNameMessage(name), locations); use
+// DuplicateOperationNameError instead to also get the message's translation key attached to
+// Extensions.
 func DuplicateOperationNameMessage(operationName string) string {
-	return fmt.Sprintf(`There can be only one operation named "%s".`, operationName)
+	return renderMessage(MessageKeyDuplicateOperationName, operationName)
+}
+
+// DuplicateOperationNameError returns the error describing a violation of rule "Operation Name
+// Uniqueness" (rules.UniqueOperationNames) at locations.
+func DuplicateOperationNameError(operationName string, locations []graphql.ErrorLocation) *graphql.Error {
+	return newMessageError(MessageKeyDuplicateOperationName, locations, operationName)
 }
 
-// AnonOperationNotAloneMessage returns message describing error occurred in rule "Lone Anonymous
-// Operation" (rules.LoneAnonymousOperation).
+// AnonOperationNotAloneMessage returns the message describing a violation of rule "Lone Anonymous
+// Operation" (rules.LoneAnonymousOperation), rendered through the registered MessageCatalog. See
+// DuplicateOperationNameMessage for how to attach it to locations.
 func AnonOperationNotAloneMessage() string {
-	return "This anonymous operation must be the only defined operation."
+	return renderMessage(MessageKeyAnonOperationNotAlone)
+}
+
+// AnonOperationNotAloneError returns the error describing a violation of rule "Lone Anonymous
+// Operation" (rules.LoneAnonymousOperation) at locations.
+func AnonOperationNotAloneError(locations []graphql.ErrorLocation) *graphql.Error {
+	return newMessageError(MessageKeyAnonOperationNotAlone, locations)
 }
 
-// SingleFieldOnlyMessage returns message describing error occurred in rule "Single Field
-// Subscriptions" (rules.SingleFieldSubscriptions).
+// SingleFieldOnlyMessage returns the message describing a violation of rule "Single Field
+// Subscriptions" (rules.SingleFieldSubscriptions), rendered through the registered MessageCatalog.
+// See DuplicateOperationNameMessage for how to attach it to locations.
 func SingleFieldOnlyMessage(name string) string {
-	if len(name) == 0 {
-		return "Anonymous Subscription must select only one top level field."
-	}
-	return fmt.Sprintf(`Subscription "%s" must select only one top level field.`, name)
+	return renderMessage(MessageKeySingleFieldOnly, name)
+}
+
+// SingleFieldOnlyError returns the error describing a violation of rule "Single Field
+// Subscriptions" (rules.SingleFieldSubscriptions) at locations.
+func SingleFieldOnlyError(name string, locations []graphql.ErrorLocation) *graphql.Error {
+	return newMessageError(MessageKeySingleFieldOnly, locations, name)
 }
@@ -0,0 +1,95 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package validator
+
+import (
+	"testing"
+)
+
+func TestDuplicateOperationNameMessageUsesEnglishCatalogByDefault(t *testing.T) {
+	got := DuplicateOperationNameMessage("Foo")
+	want := `There can be only one operation named "Foo".`
+	if got != want {
+		t.Errorf("DuplicateOperationNameMessage(\"Foo\") = %q, want %q", got, want)
+	}
+}
+
+func TestSingleFieldOnlyMessageDistinguishesAnonymous(t *testing.T) {
+	if got := SingleFieldOnlyMessage(""); got != "Anonymous Subscription must select only one top level field." {
+		t.Errorf("SingleFieldOnlyMessage(\"\") = %q, want the anonymous wording", got)
+	}
+	if got := SingleFieldOnlyMessage("MySub"); got != `Subscription "MySub" must select only one top level field.` {
+		t.Errorf("SingleFieldOnlyMessage(\"MySub\") = %q, want the named wording", got)
+	}
+}
+
+func TestDuplicateOperationNameErrorAttachesMessageKeyToExtensions(t *testing.T) {
+	err := DuplicateOperationNameError("Foo", nil)
+	info, ok := err.Extensions[messageKeyExtension].(MessageKeyInfo)
+	if !ok {
+		t.Fatalf("err.Extensions[%q] = %v, want a MessageKeyInfo", messageKeyExtension, err.Extensions[messageKeyExtension])
+	}
+	if info.Key != MessageKeyDuplicateOperationName {
+		t.Errorf("info.Key = %v, want %v", info.Key, MessageKeyDuplicateOperationName)
+	}
+	if len(info.Args) != 1 || info.Args[0] != "Foo" {
+		t.Errorf("info.Args = %v, want [\"Foo\"]", info.Args)
+	}
+}
+
+type fakeCatalog struct {
+	messages map[MessageKey]string
+}
+
+func (c fakeCatalog) Message(key MessageKey, args ...interface{}) (string, bool) {
+	msg, ok := c.messages[key]
+	return msg, ok
+}
+
+func TestRegisterMessageCatalogOverridesRendering(t *testing.T) {
+	RegisterMessageCatalog(fakeCatalog{messages: map[MessageKey]string{
+		MessageKeyAnonOperationNotAlone: "no puede estar sola",
+	}})
+	defer RegisterMessageCatalog(nil)
+
+	if got := AnonOperationNotAloneMessage(); got != "no puede estar sola" {
+		t.Errorf("AnonOperationNotAloneMessage() = %q, want the catalog's translation", got)
+	}
+}
+
+func TestRegisterMessageCatalogFallsBackToEnglishForUncoveredKeys(t *testing.T) {
+	RegisterMessageCatalog(fakeCatalog{messages: map[MessageKey]string{
+		MessageKeyAnonOperationNotAlone: "no puede estar sola",
+	}})
+	defer RegisterMessageCatalog(nil)
+
+	want := `There can be only one operation named "Foo".`
+	if got := DuplicateOperationNameMessage("Foo"); got != want {
+		t.Errorf("DuplicateOperationNameMessage(\"Foo\") = %q, want the English fallback %q", got, want)
+	}
+}
+
+func TestRegisterMessageCatalogNilRevertsToEnglish(t *testing.T) {
+	RegisterMessageCatalog(fakeCatalog{messages: map[MessageKey]string{
+		MessageKeyAnonOperationNotAlone: "no puede estar sola",
+	}})
+	RegisterMessageCatalog(nil)
+
+	if got := AnonOperationNotAloneMessage(); got != "This anonymous operation must be the only defined operation." {
+		t.Errorf("AnonOperationNotAloneMessage() after RegisterMessageCatalog(nil) = %q, want the English wording", got)
+	}
+}
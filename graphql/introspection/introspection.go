@@ -0,0 +1,147 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+// Package introspection builds the types and fields that back GraphQL's introspection system:
+// __Schema, __Type, __Field, __InputValue, __EnumValue, __Directive, __TypeKind and
+// __DirectiveLocation, plus the "__schema" and "__type" fields spliced onto a Query root and the
+// "__typename" field legal on every Object.
+//
+// The type graph (types.go) is built once, lazily, the first time any of SchemaField, TypeField
+// or TypeNameField is called; a schema that's never queried for introspection never pays to
+// construct it. SchemaField and TypeField additionally cache the *graphql.Field they build per
+// schema, since findFieldDef calls them on every field lookup against the Query root.
+package introspection
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/botobag/artemis/graphql"
+)
+
+// TypeNameField returns the synthetic "__typename" field legal on any selection set, regardless of
+// what else is selectable there (it's valid on Object, Interface and Union selections alike). Its
+// resolver simply reports parentType's name, so unlike SchemaField and TypeField it doesn't need
+// the introspection type graph at all.
+func TypeNameField(parentType graphql.Object) graphql.Field {
+	return graphql.NewField(graphql.FieldConfig{
+		Name:        "__typename",
+		Description: "The name of the current Object type at runtime.",
+		Type:        graphql.NewNonNull(graphql.String()),
+		Resolve: graphql.FieldResolverFunc(
+			func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+				return parentType.Name(), nil
+			}),
+	})
+}
+
+var (
+	rootFieldsMu sync.RWMutex
+	schemaFields = map[*graphql.Schema]graphql.Field{}
+	typeFields   = map[*graphql.Schema]graphql.Field{}
+)
+
+// SchemaField returns the "__schema" field for schema, lazily building both the introspection type
+// graph and the field itself on first use and caching the result for subsequent lookups.
+func SchemaField(schema *graphql.Schema) graphql.Field {
+	rootFieldsMu.RLock()
+	field, ok := schemaFields[schema]
+	rootFieldsMu.RUnlock()
+	if ok {
+		return field
+	}
+
+	ensureTypes()
+
+	field = graphql.NewField(graphql.FieldConfig{
+		Name:        "__schema",
+		Description: "Access the current type schema of this server.",
+		Type:        graphql.NewNonNull(schemaType),
+		Resolve: graphql.FieldResolverFunc(
+			func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+				return schema, nil
+			}),
+	})
+
+	rootFieldsMu.Lock()
+	schemaFields[schema] = field
+	rootFieldsMu.Unlock()
+
+	return field
+}
+
+// TypeField returns the "__type" field for schema, lazily building both the introspection type
+// graph and the field itself on first use and caching the result for subsequent lookups.
+func TypeField(schema *graphql.Schema) graphql.Field {
+	rootFieldsMu.RLock()
+	field, ok := typeFields[schema]
+	rootFieldsMu.RUnlock()
+	if ok {
+		return field
+	}
+
+	ensureTypes()
+
+	field = graphql.NewField(graphql.FieldConfig{
+		Name:        "__type",
+		Description: "Request the type information of a single type.",
+		Type:        typeType,
+		Args: graphql.FieldConfigArgumentMap{
+			"name": {
+				Type: graphql.NewNonNull(graphql.String()),
+			},
+		},
+		Resolve: graphql.FieldResolverFunc(
+			func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+				name, _ := info.ArgumentValues().Get("name").(string)
+				t, ok := schema.TypeMap()[name]
+				if !ok {
+					return nil, nil
+				}
+				return t, nil
+			}),
+	})
+
+	rootFieldsMu.Lock()
+	typeFields[schema] = field
+	rootFieldsMu.Unlock()
+
+	return field
+}
+
+// typeKindOf reports the __TypeKind name for t.
+func typeKindOf(t graphql.Type) string {
+	switch t.(type) {
+	case graphql.Scalar:
+		return "SCALAR"
+	case graphql.Object:
+		return "OBJECT"
+	case graphql.Interface:
+		return "INTERFACE"
+	case graphql.Union:
+		return "UNION"
+	case graphql.Enum:
+		return "ENUM"
+	case graphql.InputObject:
+		return "INPUT_OBJECT"
+	case graphql.List:
+		return "LIST"
+	case graphql.NonNull:
+		return "NON_NULL"
+	}
+	panic(fmt.Sprintf("introspection: %v has no known __TypeKind", t))
+}
@@ -0,0 +1,65 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ *
+ * TypeNameField/SchemaField/TypeField all need a constructed *graphql.Schema/graphql.ResolveInfo to
+ * drive their resolvers, which this checkout's partial core type-system snapshot can't build (see
+ * root_field_test.go in the executor package for the same limitation). typeKindOf has no such
+ * dependency, so it's the one piece of this file exercised here. graphql.Interface and
+ * graphql.InputObject are omitted below because no constructor for either is used anywhere else in
+ * this checkout to model a test fixture after.
+ */
+
+package introspection
+
+import (
+	"testing"
+
+	"github.com/botobag/artemis/graphql"
+)
+
+func TestTypeKindOf(t *testing.T) {
+	scalar := graphql.NewScalar(graphql.ScalarConfig{Name: "Custom"})
+	object := graphql.NewObject(graphql.ObjectConfig{Name: "Object"})
+	union := graphql.NewUnion(graphql.UnionConfig{Name: "Union"})
+	enum := graphql.NewEnum(graphql.EnumConfig{Name: "Enum"})
+
+	cases := []struct {
+		name string
+		t    graphql.Type
+		want string
+	}{
+		{"scalar", scalar, "SCALAR"},
+		{"object", object, "OBJECT"},
+		{"union", union, "UNION"},
+		{"enum", enum, "ENUM"},
+		{"list", graphql.NewList(scalar), "LIST"},
+		{"nonNull", graphql.NewNonNull(scalar), "NON_NULL"},
+	}
+
+	for _, c := range cases {
+		if got := typeKindOf(c.t); got != c.want {
+			t.Errorf("typeKindOf(%s) = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestTypeKindOfPanicsOnUnknownType(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("typeKindOf(nil) should panic for a type with no known __TypeKind")
+		}
+	}()
+	typeKindOf(nil)
+}
@@ -0,0 +1,475 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package introspection
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/botobag/artemis/graphql"
+)
+
+// The introspection type graph. Every one of these is mutually referential (e.g. typeType.fields
+// returns [fieldType], fieldType.type returns typeType), so they're declared here and linked up by
+// ensureTypes, which every NewObject below reaches via a graphql.FieldsThunk rather than a field
+// literal. That defers resolving the cross-references until something actually asks for a type's
+// Fields(), by which point ensureTypes has finished assigning every variable below.
+var (
+	typeKindType          graphql.Enum
+	directiveLocationType graphql.Enum
+	inputValueType        graphql.Object
+	enumValueType         graphql.Object
+	fieldType             graphql.Object
+	typeType              graphql.Object
+	directiveType         graphql.Object
+	schemaType            graphql.Object
+)
+
+var typesOnce sync.Once
+
+// ensureTypes builds the introspection type graph on first use.
+func ensureTypes() {
+	typesOnce.Do(buildTypes)
+}
+
+func buildTypes() {
+	typeKindType = graphql.NewEnum(graphql.EnumConfig{
+		Name:        "__TypeKind",
+		Description: "An enum describing what kind of type a given `__Type` is.",
+		Values: graphql.EnumValueConfigMap{
+			"SCALAR":       {Description: "Indicates this type is a scalar."},
+			"OBJECT":       {Description: "Indicates this type is an object. `fields` and `interfaces` are valid fields."},
+			"INTERFACE":    {Description: "Indicates this type is an interface. `fields`, `interfaces` and `possibleTypes` are valid fields."},
+			"UNION":        {Description: "Indicates this type is a union. `possibleTypes` is a valid field."},
+			"ENUM":         {Description: "Indicates this type is an enum. `enumValues` is a valid field."},
+			"INPUT_OBJECT": {Description: "Indicates this type is an input object. `inputFields` is a valid field."},
+			"LIST":         {Description: "Indicates this type is a list. `ofType` is a valid field."},
+			"NON_NULL":     {Description: "Indicates this type is a non-null. `ofType` is a valid field."},
+		},
+	})
+
+	directiveLocationType = graphql.NewEnum(graphql.EnumConfig{
+		Name:        "__DirectiveLocation",
+		Description: "A Directive can be adjacent to many parts of the GraphQL language, a __DirectiveLocation describes one such possible adjacency.",
+		Values: graphql.EnumValueConfigMap{
+			"QUERY":                  {Description: "Location adjacent to a query operation."},
+			"MUTATION":               {Description: "Location adjacent to a mutation operation."},
+			"SUBSCRIPTION":           {Description: "Location adjacent to a subscription operation."},
+			"FIELD":                  {Description: "Location adjacent to a field."},
+			"FRAGMENT_DEFINITION":    {Description: "Location adjacent to a fragment definition."},
+			"FRAGMENT_SPREAD":        {Description: "Location adjacent to a fragment spread."},
+			"INLINE_FRAGMENT":        {Description: "Location adjacent to an inline fragment."},
+			"VARIABLE_DEFINITION":    {Description: "Location adjacent to a variable definition."},
+			"SCHEMA":                 {Description: "Location adjacent to a schema definition."},
+			"SCALAR":                 {Description: "Location adjacent to a scalar definition."},
+			"OBJECT":                 {Description: "Location adjacent to an object type definition."},
+			"FIELD_DEFINITION":       {Description: "Location adjacent to a field definition."},
+			"ARGUMENT_DEFINITION":    {Description: "Location adjacent to an argument definition."},
+			"INTERFACE":              {Description: "Location adjacent to an interface definition."},
+			"UNION":                  {Description: "Location adjacent to a union definition."},
+			"ENUM":                   {Description: "Location adjacent to an enum definition."},
+			"ENUM_VALUE":             {Description: "Location adjacent to an enum value definition."},
+			"INPUT_OBJECT":           {Description: "Location adjacent to an input object type definition."},
+			"INPUT_FIELD_DEFINITION": {Description: "Location adjacent to an input object field definition."},
+		},
+	})
+
+	inputValueType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "__InputValue",
+		Description: "Arguments provided to Fields or Directives and the input fields of an InputObject are represented as Input Values which describe their type and optionally a default value.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"name": {
+					Type: graphql.NewNonNull(graphql.String()),
+				},
+				"description": {
+					Type: graphql.String(),
+				},
+				"type": {
+					Type: graphql.NewNonNull(typeType),
+					Resolve: graphql.FieldResolverFunc(
+						func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+							return source.(graphql.InputField).Type(), nil
+						}),
+				},
+				"defaultValue": {
+					Type:        graphql.String(),
+					Description: "A GraphQL-formatted string representing the default value for this input value.",
+					Resolve: graphql.FieldResolverFunc(
+						func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+							value, hasDefault := source.(graphql.InputField).DefaultValue()
+							if !hasDefault {
+								return nil, nil
+							}
+							return formatDefaultValue(value), nil
+						}),
+				},
+			}
+		}),
+	})
+
+	enumValueType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "__EnumValue",
+		Description: "One possible value for a given Enum. Enum values are unique values, not a placeholder for a string or numeric value. However an Enum value is returned in a JSON response as a string.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"name":        {Type: graphql.NewNonNull(graphql.String())},
+				"description": {Type: graphql.String()},
+				"isDeprecated": {
+					Type: graphql.NewNonNull(graphql.Boolean()),
+					Resolve: graphql.FieldResolverFunc(
+						func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+							return source.(graphql.EnumValue).IsDeprecated(), nil
+						}),
+				},
+				"deprecationReason": {
+					Type: graphql.String(),
+					Resolve: graphql.FieldResolverFunc(
+						func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+							return source.(graphql.EnumValue).DeprecationReason(), nil
+						}),
+				},
+			}
+		}),
+	})
+
+	fieldType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "__Field",
+		Description: "Object and Interface types are described by a list of Fields, each of which has a name, potentially a list of arguments, and a return type.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"name":        {Type: graphql.NewNonNull(graphql.String())},
+				"description": {Type: graphql.String()},
+				"args": {
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(inputValueType))),
+					Resolve: graphql.FieldResolverFunc(
+						func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+							return sortedArgs(source.(graphql.Field).Args()), nil
+						}),
+				},
+				"type": {
+					Type: graphql.NewNonNull(typeType),
+					Resolve: graphql.FieldResolverFunc(
+						func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+							return source.(graphql.Field).Type(), nil
+						}),
+				},
+				"isDeprecated": {
+					Type: graphql.NewNonNull(graphql.Boolean()),
+					Resolve: graphql.FieldResolverFunc(
+						func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+							return source.(graphql.Field).IsDeprecated(), nil
+						}),
+				},
+				"deprecationReason": {
+					Type: graphql.String(),
+					Resolve: graphql.FieldResolverFunc(
+						func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+							return source.(graphql.Field).DeprecationReason(), nil
+						}),
+				},
+			}
+		}),
+	})
+
+	directiveType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "__Directive",
+		Description: "A Directive provides a way to describe alternate runtime execution and type validation behavior in a GraphQL document.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"name":        {Type: graphql.NewNonNull(graphql.String())},
+				"description": {Type: graphql.String()},
+				"locations": {
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(directiveLocationType))),
+					Resolve: graphql.FieldResolverFunc(
+						func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+							return source.(graphql.Directive).Locations(), nil
+						}),
+				},
+				"args": {
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(inputValueType))),
+					Resolve: graphql.FieldResolverFunc(
+						func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+							return sortedArgs(source.(graphql.Directive).Args()), nil
+						}),
+				},
+			}
+		}),
+	})
+
+	typeType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "__Type",
+		Description: "The fundamental unit of any GraphQL Schema is the type. There are many kinds of types in GraphQL as represented by the `__TypeKind` enum.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"kind": {
+					Type: graphql.NewNonNull(typeKindType),
+					Resolve: graphql.FieldResolverFunc(
+						func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+							return typeKindOf(source.(graphql.Type)), nil
+						}),
+				},
+				"name": {
+					Type: graphql.String(),
+					Resolve: graphql.FieldResolverFunc(
+						func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+							if named, ok := source.(graphql.Type).(interface{ Name() string }); ok {
+								return named.Name(), nil
+							}
+							return nil, nil
+						}),
+				},
+				"description": {
+					Type: graphql.String(),
+					Resolve: graphql.FieldResolverFunc(
+						func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+							if described, ok := source.(graphql.Type).(interface{ Description() string }); ok {
+								return described.Description(), nil
+							}
+							return nil, nil
+						}),
+				},
+				"fields": {
+					Type: graphql.NewList(graphql.NewNonNull(fieldType)),
+					Args: graphql.FieldConfigArgumentMap{
+						"includeDeprecated": {
+							Type:         graphql.Boolean(),
+							DefaultValue: false,
+						},
+					},
+					Resolve: graphql.FieldResolverFunc(resolveTypeFields),
+				},
+				"interfaces": {
+					Type: graphql.NewList(graphql.NewNonNull(typeType)),
+					Resolve: graphql.FieldResolverFunc(resolveTypeInterfaces),
+				},
+				"possibleTypes": {
+					Type: graphql.NewList(graphql.NewNonNull(typeType)),
+					Resolve: graphql.FieldResolverFunc(resolveTypePossibleTypes),
+				},
+				"enumValues": {
+					Type: graphql.NewList(graphql.NewNonNull(enumValueType)),
+					Args: graphql.FieldConfigArgumentMap{
+						"includeDeprecated": {
+							Type:         graphql.Boolean(),
+							DefaultValue: false,
+						},
+					},
+					Resolve: graphql.FieldResolverFunc(resolveTypeEnumValues),
+				},
+				"inputFields": {
+					Type: graphql.NewList(graphql.NewNonNull(inputValueType)),
+					Resolve: graphql.FieldResolverFunc(resolveTypeInputFields),
+				},
+				"ofType": {
+					Type: typeType,
+					Resolve: graphql.FieldResolverFunc(
+						func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+							switch t := source.(graphql.Type).(type) {
+							case graphql.NonNull:
+								return t.InnerType(), nil
+							case graphql.List:
+								return t.ElementType(), nil
+							default:
+								return nil, nil
+							}
+						}),
+				},
+			}
+		}),
+	})
+
+	schemaType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "__Schema",
+		Description: "A GraphQL Schema defines the capabilities of a GraphQL server. It exposes all available types and directives on the server, as well as the entry points for query, mutation and subscription operations.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"types": {
+					Description: "A list of all types supported by this server.",
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(typeType))),
+					Resolve: graphql.FieldResolverFunc(
+						func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+							typeMap := source.(*graphql.Schema).TypeMap()
+							names := make([]string, 0, len(typeMap))
+							for name := range typeMap {
+								names = append(names, name)
+							}
+							sort.Strings(names)
+
+							types := make([]graphql.Type, len(names))
+							for i, name := range names {
+								types[i] = typeMap[name]
+							}
+							return types, nil
+						}),
+				},
+				"queryType": {
+					Description: "The type that query operations will be rooted at.",
+					Type:        graphql.NewNonNull(typeType),
+					Resolve: graphql.FieldResolverFunc(
+						func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+							return source.(*graphql.Schema).QueryType(), nil
+						}),
+				},
+				"mutationType": {
+					Description: "If this server supports mutation, the type that mutation operations will be rooted at.",
+					Type:        typeType,
+					Resolve: graphql.FieldResolverFunc(
+						func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+							return source.(*graphql.Schema).MutationType(), nil
+						}),
+				},
+				"subscriptionType": {
+					Description: "If this server supports subscription, the type that subscription operations will be rooted at.",
+					Type:        typeType,
+					Resolve: graphql.FieldResolverFunc(
+						func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+							return source.(*graphql.Schema).SubscriptionType(), nil
+						}),
+				},
+				"directives": {
+					Description: "A list of all directives supported by this server.",
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(directiveType))),
+					Resolve: graphql.FieldResolverFunc(
+						func(ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+							return source.(*graphql.Schema).Directives(), nil
+						}),
+				},
+			}
+		}),
+	})
+}
+
+// resolveTypeFields backs __Type.fields. It's nil (not an empty list) for anything that isn't an
+// Object or Interface, per spec.
+func resolveTypeFields(
+	ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+	includeDeprecated, _ := info.ArgumentValues().Get("includeDeprecated").(bool)
+
+	var fields map[string]graphql.Field
+	switch t := source.(graphql.Type).(type) {
+	case graphql.Object:
+		fields = t.Fields()
+	case graphql.Interface:
+		fields = t.Fields()
+	default:
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]graphql.Field, 0, len(names))
+	for _, name := range names {
+		field := fields[name]
+		if !includeDeprecated && field.IsDeprecated() {
+			continue
+		}
+		result = append(result, field)
+	}
+	return result, nil
+}
+
+// resolveTypeInterfaces backs __Type.interfaces. It's nil for anything but an Object.
+func resolveTypeInterfaces(
+	ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+	object, ok := source.(graphql.Type).(graphql.Object)
+	if !ok {
+		return nil, nil
+	}
+	return object.Interfaces(), nil
+}
+
+// resolveTypePossibleTypes backs __Type.possibleTypes. It's nil for anything but a Union or
+// Interface.
+func resolveTypePossibleTypes(
+	ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+	abstractType, ok := source.(graphql.Type).(graphql.AbstractType)
+	if !ok {
+		return nil, nil
+	}
+	return info.Schema().PossibleTypes(abstractType), nil
+}
+
+// resolveTypeEnumValues backs __Type.enumValues. It's nil for anything but an Enum.
+func resolveTypeEnumValues(
+	ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+	enum, ok := source.(graphql.Type).(graphql.Enum)
+	if !ok {
+		return nil, nil
+	}
+
+	includeDeprecated, _ := info.ArgumentValues().Get("includeDeprecated").(bool)
+	values := enum.Values()
+
+	result := make([]graphql.EnumValue, 0, len(values))
+	for _, value := range values {
+		if !includeDeprecated && value.IsDeprecated() {
+			continue
+		}
+		result = append(result, value)
+	}
+	return result, nil
+}
+
+// resolveTypeInputFields backs __Type.inputFields. It's nil for anything but an InputObject.
+func resolveTypeInputFields(
+	ctx context.Context, source interface{}, info graphql.ResolveInfo) (interface{}, error) {
+	inputObject, ok := source.(graphql.Type).(graphql.InputObject)
+	if !ok {
+		return nil, nil
+	}
+
+	fields := inputObject.Fields()
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]graphql.InputField, len(names))
+	for i, name := range names {
+		result[i] = fields[name]
+	}
+	return result, nil
+}
+
+// sortedArgs returns args sorted by name, so "__Field.args"/"__Directive.args" don't leak Go's
+// random map iteration order into the response.
+func sortedArgs(args map[string]graphql.Argument) []graphql.Argument {
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]graphql.Argument, len(names))
+	for i, name := range names {
+		result[i] = args[name]
+	}
+	return result
+}
+
+// formatDefaultValue renders an input value's coerced default as the GraphQL literal text
+// "defaultValue" is documented to contain.
+func formatDefaultValue(value interface{}) string {
+	return graphql.Print(graphql.AstFromValue(value))
+}
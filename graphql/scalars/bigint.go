@@ -0,0 +1,135 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package scalars
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/botobag/artemis/graphql"
+	"github.com/botobag/artemis/graphql/ast"
+)
+
+// Long is a custom scalar representing a 64-bit-safe integer, matching the "Long" type that
+// go-ethereum's GraphQL schema introduced for values such as gas amounts and block numbers that
+// don't fit in a standard GraphQL Int (which is limited to 32 bits per spec). Result and variable
+// values may be any of Go's signed or unsigned integer kinds, or a decimal string; the coerced
+// result is always an int64.
+var Long = graphql.NewScalar(graphql.ScalarConfig{
+	Name: "Long",
+	Description: "The `Long` scalar type represents a signed 64-bit numeric non-fractional value, " +
+		"too large to fit in the 32-bit `Int` type defined by the GraphQL specification.",
+
+	CoerceResultValue: func(value interface{}) (interface{}, error) {
+		return coerceToInt64(value)
+	},
+
+	CoerceVariableValue: func(value interface{}) (interface{}, error) {
+		return coerceToInt64(value)
+	},
+
+	ParseLiteral: func(valueAST ast.Value) (interface{}, error) {
+		switch valueAST := valueAST.(type) {
+		case *ast.IntValue:
+			return strconv.ParseInt(valueAST.Value(), 10, 64)
+		case *ast.StringValue:
+			return coerceToInt64(valueAST.Value())
+		}
+		return nil, fmt.Errorf("Long cannot represent non-integer value: %v", valueAST)
+	},
+})
+
+func coerceToInt64(value interface{}) (int64, error) {
+	switch value := value.(type) {
+	case int64:
+		return value, nil
+	case int:
+		return int64(value), nil
+	case int32:
+		return int64(value), nil
+	case uint64:
+		if value > math.MaxInt64 {
+			return 0, fmt.Errorf("Long cannot represent value larger than the max signed 64-bit integer: %v", value)
+		}
+		return int64(value), nil
+	case uint32:
+		return int64(value), nil
+	case string:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("Long cannot represent an invalid 64-bit integer string %q: %v", value, err)
+		}
+		return n, nil
+	}
+	return 0, fmt.Errorf("Long cannot represent non-integer value: %v", value)
+}
+
+// BigInt is a custom scalar representing an arbitrary-precision integer, serialized as a decimal
+// string to avoid losing precision in transports (like JSON) that model numbers as float64.
+// Result values may be a *big.Int, big.Int or a decimal string; variable and literal values must
+// be a decimal string.
+var BigInt = graphql.NewScalar(graphql.ScalarConfig{
+	Name: "BigInt",
+	Description: "The `BigInt` scalar type represents an arbitrary-precision integer, serialized " +
+		"as a decimal string to avoid precision loss in transports that model numbers as floats.",
+
+	CoerceResultValue: func(value interface{}) (interface{}, error) {
+		switch value := value.(type) {
+		case *big.Int:
+			if value == nil {
+				return nil, nil
+			}
+			return value.String(), nil
+		case big.Int:
+			return value.String(), nil
+		case string:
+			if _, ok := new(big.Int).SetString(value, 10); !ok {
+				return nil, fmt.Errorf("BigInt cannot represent an invalid decimal string %q", value)
+			}
+			return value, nil
+		}
+		return nil, fmt.Errorf("BigInt cannot represent non-integer value: %v", value)
+	},
+
+	CoerceVariableValue: func(value interface{}) (interface{}, error) {
+		return coerceToBigInt(value)
+	},
+
+	ParseLiteral: func(valueAST ast.Value) (interface{}, error) {
+		switch valueAST := valueAST.(type) {
+		case *ast.IntValue:
+			return coerceToBigInt(valueAST.Value())
+		case *ast.StringValue:
+			return coerceToBigInt(valueAST.Value())
+		}
+		return nil, fmt.Errorf("BigInt cannot represent non-integer value: %v", valueAST)
+	},
+})
+
+func coerceToBigInt(value interface{}) (*big.Int, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("BigInt cannot represent non-string value: %v", value)
+	}
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("BigInt cannot represent an invalid decimal string %q", s)
+	}
+	return n, nil
+}
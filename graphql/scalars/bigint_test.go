@@ -0,0 +1,91 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+// These tests exercise Long and BigInt's CoerceVariableValue/CoerceResultValue directly, since
+// ParseLiteral takes an ast.Value and the ast package isn't present in this checkout. coerceToInt64
+// and coerceToBigInt are tested via the scalar's exported entry points rather than by calling them
+// directly, since that's how every caller reaches them.
+package scalars
+
+import "testing"
+
+func TestLongCoerceVariableValueAcceptsIntKinds(t *testing.T) {
+	cases := []struct {
+		name string
+		in   interface{}
+		want int64
+	}{
+		{"int", int(42), 42},
+		{"int32", int32(42), 42},
+		{"int64", int64(42), 42},
+		{"uint32", uint32(42), 42},
+		{"decimal string", "42", 42},
+	}
+	for _, c := range cases {
+		got, err := Long.CoerceVariableValue(c.in)
+		if err != nil {
+			t.Errorf("Long.CoerceVariableValue(%s) returned error: %v", c.name, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("Long.CoerceVariableValue(%s) = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestLongCoerceVariableValueRejectsOutOfRangeUint64(t *testing.T) {
+	if _, err := Long.CoerceVariableValue(uint64(1) << 63); err == nil {
+		t.Error("Long.CoerceVariableValue(math.MaxInt64+1) returned no error, want overflow error")
+	}
+}
+
+func TestLongCoerceVariableValueRejectsNonInteger(t *testing.T) {
+	if _, err := Long.CoerceVariableValue("not a number"); err == nil {
+		t.Error("Long.CoerceVariableValue(\"not a number\") returned no error")
+	}
+	if _, err := Long.CoerceVariableValue(3.14); err == nil {
+		t.Error("Long.CoerceVariableValue(float64) returned no error")
+	}
+}
+
+func TestBigIntCoerceVariableValueAcceptsDecimalString(t *testing.T) {
+	got, err := BigInt.CoerceVariableValue("123456789012345678901234567890")
+	if err != nil {
+		t.Fatalf("BigInt.CoerceVariableValue(...) returned error: %v", err)
+	}
+	n, ok := got.(interface{ String() string })
+	if !ok || n.String() != "123456789012345678901234567890" {
+		t.Errorf("BigInt.CoerceVariableValue(...) = %v, want the same decimal value", got)
+	}
+}
+
+func TestBigIntCoerceVariableValueRejectsNonDecimalString(t *testing.T) {
+	if _, err := BigInt.CoerceVariableValue("not a number"); err == nil {
+		t.Error("BigInt.CoerceVariableValue(\"not a number\") returned no error")
+	}
+	if _, err := BigInt.CoerceVariableValue(42); err == nil {
+		t.Error("BigInt.CoerceVariableValue(non-string) returned no error")
+	}
+}
+
+func TestBigIntCoerceResultValueAcceptsBigIntAndString(t *testing.T) {
+	if got, err := BigInt.CoerceResultValue("42"); err != nil || got != "42" {
+		t.Errorf("BigInt.CoerceResultValue(\"42\") = (%v, %v), want (\"42\", nil)", got, err)
+	}
+	if _, err := BigInt.CoerceResultValue("not a number"); err == nil {
+		t.Error("BigInt.CoerceResultValue(\"not a number\") returned no error")
+	}
+}
@@ -0,0 +1,127 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package scalars
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/botobag/artemis/graphql"
+	"github.com/botobag/artemis/graphql/ast"
+)
+
+// JSON is a custom scalar that round-trips an opaque JSON value. Result values may be a
+// json.RawMessage or any value accepted by encoding/json.Marshal; variable values may be a
+// json.RawMessage, a string containing JSON text, or an already-decoded Go value (map[string]
+// interface{}, []interface{}, etc., as produced by a JSON transport that decoded the request
+// body itself). On the output side the coerced value is always a json.RawMessage.
+var JSON = graphql.NewScalar(graphql.ScalarConfig{
+	Name: "JSON",
+	Description: "The `JSON` scalar type represents an arbitrary JSON value, serialized and " +
+		"deserialized as-is without a fixed shape.",
+
+	CoerceResultValue: func(value interface{}) (interface{}, error) {
+		if raw, ok := value.(json.RawMessage); ok {
+			return raw, nil
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("JSON cannot represent value that failed to marshal: %v", err)
+		}
+		return json.RawMessage(encoded), nil
+	},
+
+	CoerceVariableValue: func(value interface{}) (interface{}, error) {
+		switch value := value.(type) {
+		case json.RawMessage:
+			return value, nil
+		case string:
+			if !json.Valid([]byte(value)) {
+				return nil, fmt.Errorf("JSON cannot represent an invalid JSON string %q", value)
+			}
+			return json.RawMessage(value), nil
+		default:
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("JSON cannot represent value that failed to marshal: %v", err)
+			}
+			return json.RawMessage(encoded), nil
+		}
+	},
+
+	ParseLiteral: func(valueAST ast.Value) (interface{}, error) {
+		value, err := parseLiteralToGoValue(valueAST)
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("JSON cannot represent value that failed to marshal: %v", err)
+		}
+		return json.RawMessage(encoded), nil
+	},
+})
+
+// parseLiteralToGoValue converts a GraphQL AST value node into the plain Go value
+// (map[string]interface{}, []interface{}, string, float64, bool or nil) it represents, so JSON's
+// ParseLiteral can re-serialize it uniformly regardless of the literal's shape.
+func parseLiteralToGoValue(valueAST ast.Value) (interface{}, error) {
+	switch valueAST := valueAST.(type) {
+	case *ast.NullValue:
+		return nil, nil
+	case *ast.BooleanValue:
+		return valueAST.Value(), nil
+	case *ast.IntValue:
+		n, err := strconv.ParseFloat(valueAST.Value(), 64)
+		if err != nil {
+			return nil, fmt.Errorf("JSON cannot represent an invalid integer literal %q: %v", valueAST.Value(), err)
+		}
+		return n, nil
+	case *ast.FloatValue:
+		n, err := strconv.ParseFloat(valueAST.Value(), 64)
+		if err != nil {
+			return nil, fmt.Errorf("JSON cannot represent an invalid float literal %q: %v", valueAST.Value(), err)
+		}
+		return n, nil
+	case *ast.StringValue:
+		return valueAST.Value(), nil
+	case *ast.ListValue:
+		values := valueAST.Values()
+		result := make([]interface{}, len(values))
+		for i, v := range values {
+			converted, err := parseLiteralToGoValue(v)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = converted
+		}
+		return result, nil
+	case *ast.ObjectValue:
+		fields := valueAST.Fields()
+		result := make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			converted, err := parseLiteralToGoValue(field.Value)
+			if err != nil {
+				return nil, err
+			}
+			result[field.Name.Value()] = converted
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("JSON cannot represent unexpected literal: %v", valueAST)
+}
@@ -0,0 +1,78 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package scalars
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONCoerceVariableValuePassesThroughRawMessage(t *testing.T) {
+	raw := json.RawMessage(`{"a":1}`)
+	got, err := JSON.CoerceVariableValue(raw)
+	if err != nil {
+		t.Fatalf("JSON.CoerceVariableValue(RawMessage) returned error: %v", err)
+	}
+	if string(got.(json.RawMessage)) != string(raw) {
+		t.Errorf("JSON.CoerceVariableValue(RawMessage) = %s, want %s", got, raw)
+	}
+}
+
+func TestJSONCoerceVariableValueValidatesJSONStrings(t *testing.T) {
+	if _, err := JSON.CoerceVariableValue(`{not valid json`); err == nil {
+		t.Error("JSON.CoerceVariableValue(invalid JSON string) returned no error")
+	}
+
+	got, err := JSON.CoerceVariableValue(`{"a":1}`)
+	if err != nil {
+		t.Fatalf("JSON.CoerceVariableValue(valid JSON string) returned error: %v", err)
+	}
+	if string(got.(json.RawMessage)) != `{"a":1}` {
+		t.Errorf("JSON.CoerceVariableValue(valid JSON string) = %s, want {\"a\":1}", got)
+	}
+}
+
+func TestJSONCoerceVariableValueMarshalsDecodedValues(t *testing.T) {
+	got, err := JSON.CoerceVariableValue(map[string]interface{}{"a": float64(1)})
+	if err != nil {
+		t.Fatalf("JSON.CoerceVariableValue(decoded map) returned error: %v", err)
+	}
+	if string(got.(json.RawMessage)) != `{"a":1}` {
+		t.Errorf("JSON.CoerceVariableValue(decoded map) = %s, want {\"a\":1}", got)
+	}
+}
+
+func TestJSONCoerceResultValuePassesThroughRawMessage(t *testing.T) {
+	raw := json.RawMessage(`[1,2,3]`)
+	got, err := JSON.CoerceResultValue(raw)
+	if err != nil {
+		t.Fatalf("JSON.CoerceResultValue(RawMessage) returned error: %v", err)
+	}
+	if string(got.(json.RawMessage)) != string(raw) {
+		t.Errorf("JSON.CoerceResultValue(RawMessage) = %s, want %s", got, raw)
+	}
+}
+
+func TestJSONCoerceResultValueMarshalsArbitraryValues(t *testing.T) {
+	got, err := JSON.CoerceResultValue([]int{1, 2, 3})
+	if err != nil {
+		t.Fatalf("JSON.CoerceResultValue([]int) returned error: %v", err)
+	}
+	if string(got.(json.RawMessage)) != `[1,2,3]` {
+		t.Errorf("JSON.CoerceResultValue([]int) = %s, want [1,2,3]", got)
+	}
+}
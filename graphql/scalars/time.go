@@ -0,0 +1,84 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+// Package scalars provides ready-to-use *graphql.Scalar definitions for custom scalar types that
+// most GraphQL services end up reimplementing on their own: Time, BigInt/Long and JSON. Each
+// integrates with graphql/value.CoerceValue out of the box.
+package scalars
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/botobag/artemis/graphql"
+	"github.com/botobag/artemis/graphql/ast"
+)
+
+// Time is a custom scalar that represents an instant in time, serialized as an RFC 3339 string
+// (optionally with fractional seconds and a timezone offset). Result values may be a time.Time (or
+// a fmt.Stringer producing RFC 3339 text); variable and literal values must be a string in that
+// format.
+var Time = graphql.NewScalar(graphql.ScalarConfig{
+	Name: "Time",
+	Description: "The `Time` scalar type represents a point in time as described by the RFC 3339 " +
+		"profile of ISO 8601, e.g. \"2019-09-01T12:00:00Z\" or \"2019-09-01T12:00:00.123+08:00\".",
+
+	CoerceResultValue: func(value interface{}) (interface{}, error) {
+		switch value := value.(type) {
+		case time.Time:
+			return value.Format(time.RFC3339Nano), nil
+		case *time.Time:
+			if value == nil {
+				return nil, nil
+			}
+			return value.Format(time.RFC3339Nano), nil
+		case string:
+			// Already serialized (e.g. a resolver that read it straight out of a database column).
+			// Round-trip through time.Parse to reject malformed values early.
+			if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+				return nil, fmt.Errorf("Time cannot represent value %v: %v", value, err)
+			}
+			return value, nil
+		}
+		return nil, fmt.Errorf("Time cannot represent non-time value: %v", value)
+	},
+
+	CoerceVariableValue: func(value interface{}) (interface{}, error) {
+		switch value := value.(type) {
+		case time.Time:
+			return value, nil
+		case string:
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return nil, fmt.Errorf("Time cannot represent an invalid RFC 3339 string %q: %v", value, err)
+			}
+			return t, nil
+		}
+		return nil, fmt.Errorf("Time cannot represent non-string, non-time value: %v", value)
+	},
+
+	ParseLiteral: func(valueAST ast.Value) (interface{}, error) {
+		s, ok := valueAST.(*ast.StringValue)
+		if !ok {
+			return nil, fmt.Errorf("Time cannot represent non-string value: %v", valueAST)
+		}
+		t, err := time.Parse(time.RFC3339Nano, s.Value())
+		if err != nil {
+			return nil, fmt.Errorf("Time cannot represent an invalid RFC 3339 string %q: %v", s.Value(), err)
+		}
+		return t, nil
+	},
+})
@@ -0,0 +1,67 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package scalars
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeCoerceResultValueFormatsRFC3339(t *testing.T) {
+	moment := time.Date(2026, 7, 30, 12, 0, 0, 0, time.UTC)
+
+	got, err := Time.CoerceResultValue(moment)
+	if err != nil {
+		t.Fatalf("Time.CoerceResultValue(time.Time) returned error: %v", err)
+	}
+	if got != "2026-07-30T12:00:00Z" {
+		t.Errorf("Time.CoerceResultValue(time.Time) = %v, want 2026-07-30T12:00:00Z", got)
+	}
+
+	if got, err := Time.CoerceResultValue((*time.Time)(nil)); err != nil || got != nil {
+		t.Errorf("Time.CoerceResultValue(nil *time.Time) = (%v, %v), want (nil, nil)", got, err)
+	}
+}
+
+func TestTimeCoerceResultValueRoundTripsValidString(t *testing.T) {
+	if got, err := Time.CoerceResultValue("2026-07-30T12:00:00Z"); err != nil || got != "2026-07-30T12:00:00Z" {
+		t.Errorf("Time.CoerceResultValue(valid RFC 3339 string) = (%v, %v), want the same string", got, err)
+	}
+	if _, err := Time.CoerceResultValue("not a time"); err == nil {
+		t.Error("Time.CoerceResultValue(\"not a time\") returned no error")
+	}
+}
+
+func TestTimeCoerceVariableValueParsesRFC3339String(t *testing.T) {
+	got, err := Time.CoerceVariableValue("2026-07-30T12:00:00Z")
+	if err != nil {
+		t.Fatalf("Time.CoerceVariableValue(valid RFC 3339 string) returned error: %v", err)
+	}
+	want := time.Date(2026, 7, 30, 12, 0, 0, 0, time.UTC)
+	if !got.(time.Time).Equal(want) {
+		t.Errorf("Time.CoerceVariableValue(...) = %v, want %v", got, want)
+	}
+}
+
+func TestTimeCoerceVariableValueRejectsInvalidInput(t *testing.T) {
+	if _, err := Time.CoerceVariableValue("not a time"); err == nil {
+		t.Error("Time.CoerceVariableValue(\"not a time\") returned no error")
+	}
+	if _, err := Time.CoerceVariableValue(42); err == nil {
+		t.Error("Time.CoerceVariableValue(non-string, non-time) returned no error")
+	}
+}
@@ -0,0 +1,105 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package value
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/botobag/artemis/graphql"
+	"github.com/botobag/artemis/graphql/ast"
+	"github.com/botobag/artemis/internal/util"
+)
+
+// InputCoercerFunc is a hand-written (typically generated by cmd/artemis-gen) replacement for the
+// reflection-based coercion that CoerceValue performs for a particular GraphQL input type. It
+// receives the raw input value destined for that type and returns either a coerced Go value or
+// the coercion errors the reflective path would have produced for the same input.
+type InputCoercerFunc func(value interface{}) (interface{}, graphql.Errors)
+
+var (
+	inputCoercersMu sync.RWMutex
+	inputCoercers   = map[graphql.Type]InputCoercerFunc{}
+)
+
+// RegisterInputCoercer installs fn as the coercer that CoerceValue uses for t, bypassing the
+// reflection-driven coercion entirely. It is meant to be called from the init() function of a
+// package produced by cmd/artemis-gen, or from a hand-written package that binds its own Go
+// structs to InputObjects. Registering a coercer for a type that already has one replaces the
+// previous registration.
+func RegisterInputCoercer(t graphql.Type, fn InputCoercerFunc) {
+	inputCoercersMu.Lock()
+	defer inputCoercersMu.Unlock()
+	inputCoercers[t] = fn
+}
+
+// LookupInputCoercer returns the registered coercer for t, if any. It is called by CoerceValue to
+// prefer a registered coercer over the reflective path.
+func LookupInputCoercer(t graphql.Type) (InputCoercerFunc, bool) {
+	inputCoercersMu.RLock()
+	defer inputCoercersMu.RUnlock()
+	fn, ok := inputCoercers[t]
+	return fn, ok
+}
+
+// NewCoercionError builds a *graphql.Error with the same shape CoerceValue produces for the
+// reflective path, for generated or hand-written coercers to construct indistinguishable errors.
+// path should already be formatted as a value path string (e.g. "value.field[0]").
+func NewCoercionError(
+	message string,
+	blameNode ast.Node,
+	path string,
+	subMessage string,
+	originalError error) *graphql.Error {
+	var messageBuilder util.StringBuilder
+
+	messageBuilder.WriteString(message)
+	if len(path) > 0 {
+		messageBuilder.WriteString(" at ")
+		messageBuilder.WriteString(path)
+	}
+
+	if len(subMessage) > 0 {
+		messageBuilder.WriteString("; ")
+		messageBuilder.WriteString(subMessage)
+	} else {
+		messageBuilder.WriteRune('.')
+	}
+
+	var locations []graphql.ErrorLocation
+	if blameNode != nil {
+		locations = []graphql.ErrorLocation{
+			graphql.ErrorLocationOfASTNode(blameNode),
+		}
+	}
+
+	if originalError == nil {
+		return graphql.NewError(messageBuilder.String(), locations).(*graphql.Error)
+	}
+	return graphql.NewError(messageBuilder.String(), locations, originalError).(*graphql.Error)
+}
+
+// DidYouMean renders the "did you mean ...?" suggestion fragment used throughout coercion error
+// messages, given the offending name and the set of valid candidates. It returns "" when there are
+// no close matches.
+func DidYouMean(name string, candidates []string) string {
+	suggestions := util.SuggestionList(name, candidates)
+	if len(suggestions) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("did you mean %s?", util.OrList(suggestions, 5 /* maxLength */, false /* quoted */))
+}
@@ -0,0 +1,77 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package value
+
+// Nullable is implemented by wrapper types used as InputObject-bound struct fields that need to
+// distinguish GraphQL's three input states: the field wasn't provided, the field was provided and
+// explicitly null, or the field was provided with a value. A plain pointer field can only tell
+// apart two of these (a nil *T always means "not provided", so defaults apply even when the caller
+// meant "clear this"); wrapping the field in a Nullable resolves the ambiguity.
+type Nullable interface {
+	// IsSet reports whether the field was populated at all, null or not.
+	IsSet() bool
+
+	// IsNull reports whether the field was explicitly set to null. Only meaningful when IsSet
+	// returns true.
+	IsNull() bool
+
+	// Value returns the field's value. Only meaningful when IsSet returns true and IsNull returns
+	// false.
+	Value() interface{}
+}
+
+// Null is a ready-made Nullable wrapper for use as an InputObject-bound struct field, e.g.:
+//
+//	type UpdateUserInput struct {
+//		// Left as the zero value, Name is reported as "not provided".
+//		Name value.Null
+//	}
+//
+//	input.Name = value.NewNull("alice")  // "provided with value"
+//	input.Name = value.NewNullNull()     // "provided, explicitly null"
+type Null struct {
+	set   bool
+	null  bool
+	value interface{}
+}
+
+var _ Nullable = Null{}
+
+// NewNull returns a Null wrapper reporting a present, non-null value v.
+func NewNull(v interface{}) Null {
+	return Null{set: true, value: v}
+}
+
+// NewNullNull returns a Null wrapper reporting a present, explicitly null value.
+func NewNullNull() Null {
+	return Null{set: true, null: true}
+}
+
+// IsSet implements Nullable.
+func (n Null) IsSet() bool {
+	return n.set
+}
+
+// IsNull implements Nullable.
+func (n Null) IsNull() bool {
+	return n.set && n.null
+}
+
+// Value implements Nullable.
+func (n Null) Value() interface{} {
+	return n.value
+}
@@ -0,0 +1,52 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package value
+
+import "testing"
+
+func TestNullZeroValueIsAbsent(t *testing.T) {
+	var n Null
+	if n.IsSet() {
+		t.Error("zero-value Null.IsSet() = true, want false")
+	}
+	if n.IsNull() {
+		t.Error("zero-value Null.IsNull() = true, want false")
+	}
+}
+
+func TestNewNullReportsPresentNonNull(t *testing.T) {
+	n := NewNull("alice")
+	if !n.IsSet() {
+		t.Error("NewNull(...).IsSet() = false, want true")
+	}
+	if n.IsNull() {
+		t.Error("NewNull(...).IsNull() = true, want false")
+	}
+	if n.Value() != "alice" {
+		t.Errorf("NewNull(\"alice\").Value() = %v, want \"alice\"", n.Value())
+	}
+}
+
+func TestNewNullNullReportsPresentNull(t *testing.T) {
+	n := NewNullNull()
+	if !n.IsSet() {
+		t.Error("NewNullNull().IsSet() = false, want true")
+	}
+	if !n.IsNull() {
+		t.Error("NewNullNull().IsNull() = false, want true")
+	}
+}
@@ -0,0 +1,134 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package value
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// structTag is the struct tag that opts a field into explicit GraphQL input-object field name
+// matching (e.g. `graphql:"fieldName"`), matched against the InputObject field name exactly. When
+// absent, the field's Go name is matched case-insensitively against the InputObject field name.
+const structTag = "graphql"
+
+// structFields describes how an InputObject's fields map onto a Go struct's fields. It is built
+// once per (reflect.Type, set of field names) and cached in structFieldCache so that repeated
+// coercion of the same Go type doesn't pay for repeated reflection.
+type structFields struct {
+	// tagged maps an explicit `graphql:"..."` tag value to its field index, matched exactly.
+	tagged map[string]int
+
+	// untagged maps an untagged field's Go name, lowercased, to its field index, for
+	// case-insensitive fallback matching.
+	untagged map[string]int
+}
+
+// structFieldCache memoizes structFields by the struct's reflect.Type so CoerceValue's reflective
+// fast path only inspects a Go type's fields once.
+var structFieldCache sync.Map // map[reflect.Type]structFields
+
+// fieldsOfStruct returns the structFields mapping for t (which must be a struct type), building
+// and caching it on first use.
+func fieldsOfStruct(t reflect.Type) structFields {
+	if cached, ok := structFieldCache.Load(t); ok {
+		return cached.(structFields)
+	}
+
+	fields := structFields{
+		tagged:   map[string]int{},
+		untagged: map[string]int{},
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// Unexported fields cannot be set via reflection and are not addressable by callers.
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name := field.Tag.Get(structTag); name != "" {
+			if name != "-" {
+				fields.tagged[name] = i
+			}
+			continue
+		}
+		fields.untagged[strings.ToLower(field.Name)] = i
+	}
+
+	// It's fine if another goroutine raced us to build the same entry; LoadOrStore keeps whichever
+	// was stored first so all callers observe a single, consistent mapping.
+	actual, _ := structFieldCache.LoadOrStore(t, fields)
+	return actual.(structFields)
+}
+
+// isStructOrPointerToStruct reports whether value is a struct or a non-nil pointer to a struct, and
+// returns the reflect.Value of the struct itself (dereferencing the pointer, if any).
+func isStructOrPointerToStruct(value interface{}) (reflect.Value, bool) {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	return v, true
+}
+
+// nullableType is the reflect.Type of Nullable, used to detect struct fields that opt into the
+// three-way present/null/absent distinction.
+var nullableType = reflect.TypeOf((*Nullable)(nil)).Elem()
+
+// lookupStructField returns the value of the struct field bound to the InputObject field named
+// name, whether the Go struct provides one at all, and whether the field was explicitly set to
+// null (as opposed to merely absent).
+//
+// A field whose type implements Nullable reports all three GraphQL input states as the wrapper
+// describes them. Otherwise, a field of pointer, slice, map or interface kind that holds its zero
+// value is reported as "not provided" (it has no way to additionally express "provided as null")
+// so default values and non-null validation still apply; every other field kind is always
+// considered provided, since Go has no way to distinguish a present zero value from an absent one
+// for those kinds.
+func lookupStructField(structValue reflect.Value, fields structFields, name string) (value interface{}, present bool, isNull bool) {
+	index, ok := fields.tagged[name]
+	if !ok {
+		index, ok = fields.untagged[strings.ToLower(name)]
+		if !ok {
+			return nil, false, false
+		}
+	}
+
+	fieldValue := structValue.Field(index)
+	if fieldValue.Type().Implements(nullableType) {
+		nullable := fieldValue.Interface().(Nullable)
+		if !nullable.IsSet() {
+			return nil, false, false
+		}
+		return nullable.Value(), true, nullable.IsNull()
+	}
+
+	switch fieldValue.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		if fieldValue.IsZero() {
+			return nil, false, false
+		}
+	}
+	return fieldValue.Interface(), true, false
+}
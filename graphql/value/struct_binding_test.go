@@ -0,0 +1,123 @@
+/**
+ * Copyright (c) 2019, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package value
+
+import (
+	"reflect"
+	"testing"
+)
+
+type bindingTestStruct struct {
+	Name    string
+	Email   string `graphql:"emailAddress"`
+	private string
+}
+
+func TestFieldsOfStructMatchesUntaggedCaseInsensitively(t *testing.T) {
+	fields := fieldsOfStruct(reflect.TypeOf(bindingTestStruct{}))
+
+	index, ok := fields.untagged["name"]
+	if !ok {
+		t.Fatal(`fieldsOfStruct: "name" not found in untagged map`)
+	}
+	if got := reflect.TypeOf(bindingTestStruct{}).Field(index).Name; got != "Name" {
+		t.Errorf("untagged[\"name\"] resolved to field %q, want \"Name\"", got)
+	}
+}
+
+func TestFieldsOfStructMatchesExplicitTagExactly(t *testing.T) {
+	fields := fieldsOfStruct(reflect.TypeOf(bindingTestStruct{}))
+
+	if _, ok := fields.tagged["emailAddress"]; !ok {
+		t.Fatal(`fieldsOfStruct: tagged["emailAddress"] missing for field with graphql:"emailAddress"`)
+	}
+	// A struct-tagged field is matched only by its tag, not by its Go name.
+	if _, ok := fields.untagged["email"]; ok {
+		t.Error(`fieldsOfStruct: tagged field "Email" leaked into the untagged map under its Go name`)
+	}
+}
+
+func TestFieldsOfStructSkipsUnexportedFields(t *testing.T) {
+	fields := fieldsOfStruct(reflect.TypeOf(bindingTestStruct{}))
+
+	if _, ok := fields.untagged["private"]; ok {
+		t.Error("fieldsOfStruct: unexported field \"private\" should not be reachable")
+	}
+}
+
+func TestIsStructOrPointerToStructDereferencesPointer(t *testing.T) {
+	s := bindingTestStruct{Name: "alice"}
+
+	if _, ok := isStructOrPointerToStruct(s); !ok {
+		t.Error("isStructOrPointerToStruct(struct) = false, want true")
+	}
+	if v, ok := isStructOrPointerToStruct(&s); !ok || v.Interface().(bindingTestStruct).Name != "alice" {
+		t.Error("isStructOrPointerToStruct(*struct) did not dereference to the pointed-to struct")
+	}
+	if _, ok := isStructOrPointerToStruct((*bindingTestStruct)(nil)); ok {
+		t.Error("isStructOrPointerToStruct(nil pointer) = true, want false")
+	}
+	if _, ok := isStructOrPointerToStruct(42); ok {
+		t.Error("isStructOrPointerToStruct(non-struct) = true, want false")
+	}
+}
+
+func TestLookupStructFieldThreeWayNullable(t *testing.T) {
+	type input struct {
+		Name Null
+	}
+	fields := fieldsOfStruct(reflect.TypeOf(input{}))
+
+	absent := input{}
+	if _, present, _ := lookupStructField(reflect.ValueOf(absent), fields, "name"); present {
+		t.Error("zero-value Null field reported present, want absent")
+	}
+
+	explicitNull := input{Name: NewNullNull()}
+	if value, present, isNull := lookupStructField(reflect.ValueOf(explicitNull), fields, "name"); !present || !isNull || value != nil {
+		t.Errorf("NewNullNull() field = (%v, %v, %v), want (nil, true, true)", value, present, isNull)
+	}
+
+	withValue := input{Name: NewNull("alice")}
+	if value, present, isNull := lookupStructField(reflect.ValueOf(withValue), fields, "name"); !present || isNull || value != "alice" {
+		t.Errorf("NewNull(\"alice\") field = (%v, %v, %v), want (\"alice\", true, false)", value, present, isNull)
+	}
+}
+
+func TestLookupStructFieldZeroValuePointerIsAbsent(t *testing.T) {
+	type input struct {
+		Name *string
+	}
+	fields := fieldsOfStruct(reflect.TypeOf(input{}))
+
+	if _, present, _ := lookupStructField(reflect.ValueOf(input{}), fields, "name"); present {
+		t.Error("nil *string field reported present, want absent (pointers can't express explicit null)")
+	}
+
+	name := "alice"
+	if value, present, _ := lookupStructField(reflect.ValueOf(input{Name: &name}), fields, "name"); !present || value.(*string) != &name {
+		t.Error("non-nil *string field not reported as present with its value")
+	}
+}
+
+func TestLookupStructFieldUnknownNameIsAbsent(t *testing.T) {
+	fields := fieldsOfStruct(reflect.TypeOf(bindingTestStruct{}))
+
+	if _, present, _ := lookupStructField(reflect.ValueOf(bindingTestStruct{}), fields, "doesNotExist"); present {
+		t.Error("lookupStructField for an unmapped name reported present, want absent")
+	}
+}
@@ -65,15 +65,53 @@ func (path *valuePath) Empty() bool {
 	return path == nil
 }
 
+// CoerceValueOptions customizes the error-collection behavior of CoerceValueWithOptions.
+type CoerceValueOptions struct {
+	// CollectAllErrors, when true, makes List and InputObject coercion keep walking every remaining
+	// element or field and collecting their errors instead of stopping at the first one, so a
+	// caller doing "best effort" validation (e.g. an admin tool reporting every problem in a bulk
+	// input at once) can see them all in a single pass. The partial result — with unsuccessfully
+	// coerced elements/fields omitted — is returned alongside the errors in that case.
+	CollectAllErrors bool
+
+	// MaxErrors caps how many errors are collected before coercion gives up early. Zero (the
+	// default) means unlimited. Only meaningful when CollectAllErrors is true.
+	MaxErrors int
+}
+
+func (opts *CoerceValueOptions) collectAllErrors() bool {
+	return opts != nil && opts.CollectAllErrors
+}
+
+func (opts *CoerceValueOptions) reachedMaxErrors(numErrors int) bool {
+	return opts != nil && opts.MaxErrors > 0 && numErrors >= opts.MaxErrors
+}
+
 // CoerceValue coerces a Go value given a GraphQL Type.
 //
 // Returns either a value which is valid for the provided type or a list of encountered coercion
-// errors.
+// errors. It stops at the first coercion error it encounters; use CoerceValueWithOptions to
+// collect every error in a List or InputObject instead.
 func CoerceValue(value interface{}, t graphql.Type, blameNode ast.Node) (interface{}, graphql.Errors) {
-	return coerceValueImpl(value, t, blameNode, nil)
+	return coerceValueImpl(value, t, blameNode, nil, nil)
+}
+
+// CoerceValueWithOptions is CoerceValue with error-collection behavior customized via opts. See
+// CoerceValueOptions.
+func CoerceValueWithOptions(
+	value interface{},
+	t graphql.Type,
+	blameNode ast.Node,
+	opts *CoerceValueOptions) (interface{}, graphql.Errors) {
+	return coerceValueImpl(value, t, blameNode, nil, opts)
 }
 
-func coerceValueImpl(value interface{}, t graphql.Type, blameNode ast.Node, path *valuePath) (interface{}, graphql.Errors) {
+func coerceValueImpl(
+	value interface{},
+	t graphql.Type,
+	blameNode ast.Node,
+	path *valuePath,
+	opts *CoerceValueOptions) (interface{}, graphql.Errors) {
 	// A value must be provided if the type is non-null.
 	if nonNullType, isNonNullType := t.(*graphql.NonNull); isNonNullType {
 		if value == nil {
@@ -86,7 +124,7 @@ func coerceValueImpl(value interface{}, t graphql.Type, blameNode ast.Node, path
 					nil, /* originalError */
 				)}
 		}
-		return coerceValueImpl(value, nonNullType.InnerType(), blameNode, path)
+		return coerceValueImpl(value, nonNullType.InnerType(), blameNode, path, opts)
 	}
 
 	if value == nil {
@@ -94,6 +132,12 @@ func coerceValueImpl(value interface{}, t graphql.Type, blameNode ast.Node, path
 		return nil, nil
 	}
 
+	// Prefer a coercer registered by generated code (see value.RegisterInputCoercer) over the
+	// reflective path below: it's hand-rolled for this exact type and doesn't pay reflection cost.
+	if fn, ok := LookupInputCoercer(t); ok {
+		return fn(value)
+	}
+
 	switch t := t.(type) {
 	case *graphql.Scalar:
 		// Scalars determine if a value is valid via CoerceVariableValue(), which returns error to
@@ -156,56 +200,99 @@ func coerceValueImpl(value interface{}, t graphql.Type, blameNode ast.Node, path
 			// Allocate a path key for adding list index to the path.
 			path := path.NewListIndex(0)
 			for i := 0; i < numElements; i++ {
+				if opts.reachedMaxErrors(len(errs)) {
+					break
+				}
 				path.key = i
 				coercedValue, elementErrs := coerceValueImpl(
 					reflectValue.Index(i).Interface(),
 					elementType,
 					blameNode,
-					path)
+					path,
+					opts)
 				if len(elementErrs) > 0 {
 					errs = append(errs, elementErrs...)
-				} else if len(errs) == 0 {
+					if !opts.collectAllErrors() {
+						break
+					}
+				} else if len(errs) == 0 || opts.collectAllErrors() {
 					coercedValues = append(coercedValues, coercedValue)
 				}
 			}
 			if len(errs) > 0 {
+				if opts.collectAllErrors() {
+					return coercedValues, errs
+				}
 				return nil, errs
 			}
 			return coercedValues, nil
 		}
 
 		// Lists accept a non-list value as a list of one.
-		coercedValue, errs := coerceValueImpl(value, elementType, blameNode, path)
+		coercedValue, errs := coerceValueImpl(value, elementType, blameNode, path, opts)
 		if len(errs) > 0 {
 			return nil, errs
 		}
 		return []interface{}{coercedValue}, nil
 
 	case *graphql.InputObject:
-		// Currently we only accept map[string]interface{}. See #52.
+		// Accept either a map[string]interface{} or a Go struct (or pointer to one); the latter is
+		// matched to InputObject fields via the `graphql` struct tag, falling back to the Go field
+		// name. See #52.
 		objectValue, isObjectValue := value.(map[string]interface{})
+		structValue, fields, isStructValue := reflect.Value{}, structFields{}, false
 		if !isObjectValue {
-			return nil, graphql.Errors{
-				newCoercionError(
-					fmt.Sprintf(`Expected type %s to be an object`, t.String()),
-					blameNode,
-					path,
-					"", /* subMessage */
-					graphql.NewError(fmt.Sprintf("value for InputObject should be given in a map[string]interface{}, but got: %T", value)),
-				)}
+			structValue, isStructValue = isStructOrPointerToStruct(value)
+			if !isStructValue {
+				return nil, graphql.Errors{
+					newCoercionError(
+						fmt.Sprintf(`Expected type %s to be an object`, t.String()),
+						blameNode,
+						path,
+						"", /* subMessage */
+						graphql.NewError(fmt.Sprintf("value for InputObject should be given in a map[string]interface{} or a struct, but got: %T", value)),
+					)}
+			}
+			fields = fieldsOfStruct(structValue.Type())
 		}
 
 		var errs graphql.Errors
-		fields := t.Fields()
-		coercedValue := make(map[string]interface{}, len(fields))
+		typeFields := t.Fields()
+		coercedValue := make(map[string]interface{}, len(typeFields))
 		// Allocate a path key for adding field name to the path.
 		path := path.NewObjectField("")
 
 		// Ensure every defined field is valid.
-		for name, field := range fields {
-			fieldValue, hasFieldValue := objectValue[name]
+		for name, field := range typeFields {
+			if opts.reachedMaxErrors(len(errs)) {
+				break
+			}
+
+			var (
+				fieldValue          interface{}
+				hasFieldValue       bool
+				fieldIsExplicitNull bool
+			)
+			if isObjectValue {
+				fieldValue, hasFieldValue = objectValue[name]
+			} else {
+				fieldValue, hasFieldValue, fieldIsExplicitNull = lookupStructField(structValue, fields, name)
+			}
+
 			path.key = name
-			if !hasFieldValue {
+			if fieldIsExplicitNull {
+				// The struct field was wrapped in a Nullable and explicitly set to null; coerce nil so
+				// that non-null validation still fires for fields that don't accept it.
+				coercedField, fieldErrs := coerceValueImpl(nil, field.Type(), blameNode, path, opts)
+				if len(fieldErrs) > 0 {
+					errs = append(errs, fieldErrs...)
+					if !opts.collectAllErrors() {
+						break
+					}
+				} else if len(errs) == 0 || opts.collectAllErrors() {
+					coercedValue[name] = coercedField
+				}
+			} else if !hasFieldValue {
 				if field.HasDefaultValue() {
 					coercedValue[name] = field.DefaultValue()
 				} else if graphql.IsNonNullType(field.Type()) {
@@ -217,12 +304,18 @@ func coerceValueImpl(value interface{}, t graphql.Type, blameNode ast.Node, path
 							"",  /* subMessage */
 							nil, /* originalError */
 						))
+					if !opts.collectAllErrors() {
+						break
+					}
 				}
 			} else {
-				coercedField, fieldErrs := coerceValueImpl(fieldValue, field.Type(), blameNode, path)
+				coercedField, fieldErrs := coerceValueImpl(fieldValue, field.Type(), blameNode, path, opts)
 				if len(fieldErrs) > 0 {
 					errs = append(errs, fieldErrs...)
-				} else if len(errs) == 0 {
+					if !opts.collectAllErrors() {
+						break
+					}
+				} else if len(errs) == 0 || opts.collectAllErrors() {
 					coercedValue[name] = coercedField
 				}
 			}
@@ -231,36 +324,49 @@ func coerceValueImpl(value interface{}, t graphql.Type, blameNode ast.Node, path
 		// Restore path.
 		path = path.prev
 
-		// Ensure every provided field is defined.
-		var fieldNames []string
-		for name := range objectValue {
-			_, exists := fields[name]
-			if !exists {
-				if fieldNames == nil {
-					// Collect field names.
-					fieldNames = make([]string, 0, len(fields))
-					for name := range fields {
-						fieldNames = append(fieldNames, name)
-					}
-				}
-				suggestions := util.SuggestionList(name, fieldNames)
-				var didYouMean string
-				if len(suggestions) > 0 {
-					didYouMean = fmt.Sprintf("did you mean %s?", util.OrList(suggestions, 5 /* maxLength*/, false /*quoted*/))
+		// Ensure every provided field is defined. This check only makes sense for the untyped
+		// map[string]interface{} representation: a Go struct's fields are defined by its type, not by
+		// the InputObject, so there's nothing analogous to "an extra key in the map" to reject.
+		if isObjectValue {
+			var fieldNames []string
+			for name := range objectValue {
+				if opts.reachedMaxErrors(len(errs)) {
+					break
 				}
+				_, exists := typeFields[name]
+				if !exists {
+					if fieldNames == nil {
+						// Collect field names.
+						fieldNames = make([]string, 0, len(typeFields))
+						for name := range typeFields {
+							fieldNames = append(fieldNames, name)
+						}
+					}
+					suggestions := util.SuggestionList(name, fieldNames)
+					var didYouMean string
+					if len(suggestions) > 0 {
+						didYouMean = fmt.Sprintf("did you mean %s?", util.OrList(suggestions, 5 /* maxLength*/, false /*quoted*/))
+					}
 
-				errs = append(errs,
-					newCoercionError(
-						fmt.Sprintf(`Field "%s" is not defined by type %s`, name, t.String()),
-						blameNode,
-						path,
-						didYouMean,
-						nil, /* originalError */
-					))
+					errs = append(errs,
+						newCoercionError(
+							fmt.Sprintf(`Field "%s" is not defined by type %s`, name, t.String()),
+							blameNode,
+							path,
+							didYouMean,
+							nil, /* originalError */
+						))
+					if !opts.collectAllErrors() {
+						break
+					}
+				}
 			}
 		}
 
 		if len(errs) > 0 {
+			if opts.collectAllErrors() {
+				return coercedValue, errs
+			}
 			return nil, errs
 		}
 		return coercedValue, nil
@@ -277,43 +383,18 @@ func coerceValueImpl(value interface{}, t graphql.Type, blameNode ast.Node, path
 	}
 }
 
+// newCoercionError delegates to NewCoercionError, the shared builder also used by generated and
+// hand-written coercers, after rendering path (which those callers don't have, and so format as a
+// plain string themselves) via valuePath.String().
 func newCoercionError(
 	message string,
 	blameNode ast.Node,
 	path *valuePath,
 	subMessage string,
 	originalError error) *graphql.Error {
-	var messageBuilder util.StringBuilder
-
-	messageBuilder.WriteString(message)
+	var pathString string
 	if !path.Empty() {
-		messageBuilder.WriteString(" at ")
-		messageBuilder.WriteString(path.String())
+		pathString = path.String()
 	}
-
-	if len(subMessage) > 0 {
-		messageBuilder.WriteString("; ")
-		messageBuilder.WriteString(subMessage)
-	} else {
-		messageBuilder.WriteRune('.')
-	}
-
-	var locations []graphql.ErrorLocation
-	if blameNode != nil {
-		locations = []graphql.ErrorLocation{
-			graphql.ErrorLocationOfASTNode(blameNode),
-		}
-	}
-
-	if originalError == nil {
-		// XXX
-		return graphql.NewError(
-			messageBuilder.String(),
-			locations).(*graphql.Error)
-	}
-
-	return graphql.NewError(
-		messageBuilder.String(),
-		locations,
-		originalError).(*graphql.Error)
+	return NewCoercionError(message, blameNode, pathString, subMessage, originalError)
 }
@@ -0,0 +1,91 @@
+/**
+ * Copyright (c) 2018, The Artemis Authors.
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package value
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/botobag/artemis/graphql"
+)
+
+// oddRejectingInt is a toy scalar whose CoerceVariableValue rejects odd numbers, giving the tests
+// below a cheap way to make specific list elements fail coercion.
+var oddRejectingInt = graphql.NewScalar(graphql.ScalarConfig{
+	Name: "OddRejectingInt",
+	CoerceVariableValue: func(value interface{}) (interface{}, error) {
+		n, ok := value.(int)
+		if !ok {
+			return nil, fmt.Errorf("OddRejectingInt cannot represent non-int value: %v", value)
+		}
+		if n%2 != 0 {
+			return nil, fmt.Errorf("OddRejectingInt cannot represent odd value: %d", n)
+		}
+		return n, nil
+	},
+})
+
+func TestCoerceValueStopsAtFirstErrorByDefault(t *testing.T) {
+	listType := graphql.NewList(oddRejectingInt)
+
+	_, errs := CoerceValue([]interface{}{1, 2, 3, 4, 5}, listType, nil)
+	if len(errs) != 1 {
+		t.Fatalf("CoerceValue(default options) returned %d errors, want 1 (stop at first)", len(errs))
+	}
+}
+
+func TestCoerceValueWithOptionsCollectAllErrorsReturnsPartialResult(t *testing.T) {
+	listType := graphql.NewList(oddRejectingInt)
+
+	result, errs := CoerceValueWithOptions(
+		[]interface{}{1, 2, 3, 4, 5}, listType, nil, &CoerceValueOptions{CollectAllErrors: true})
+
+	if len(errs) != 3 {
+		t.Fatalf("CollectAllErrors: got %d errors, want 3 (one per odd element)", len(errs))
+	}
+
+	coerced, ok := result.([]interface{})
+	if !ok {
+		t.Fatalf("CollectAllErrors: result = %T, want []interface{}", result)
+	}
+	if len(coerced) != 2 || coerced[0] != 2 || coerced[1] != 4 {
+		t.Errorf("CollectAllErrors: partial result = %v, want the two successfully coerced elements [2 4]", coerced)
+	}
+}
+
+func TestCoerceValueWithOptionsMaxErrorsCapsCollection(t *testing.T) {
+	listType := graphql.NewList(oddRejectingInt)
+
+	_, errs := CoerceValueWithOptions(
+		[]interface{}{1, 3, 5, 7, 9},
+		listType,
+		nil,
+		&CoerceValueOptions{CollectAllErrors: true, MaxErrors: 2})
+
+	if len(errs) != 2 {
+		t.Fatalf("MaxErrors: 2: got %d errors, want exactly 2", len(errs))
+	}
+}
+
+func TestCoerceValueOptionsNilBehavesLikeCoerceValue(t *testing.T) {
+	listType := graphql.NewList(oddRejectingInt)
+
+	_, errs := CoerceValueWithOptions([]interface{}{1, 2, 3, 4, 5}, listType, nil, nil)
+	if len(errs) != 1 {
+		t.Errorf("CoerceValueWithOptions(nil opts) returned %d errors, want 1 (stop at first, same as CoerceValue)", len(errs))
+	}
+}